@@ -0,0 +1,115 @@
+package phoenix
+
+import "context"
+
+const assignsKey = CtxKey("phoenix.assigns")
+
+// Assigns is a bag of request scoped values, it is set by middlewares and can
+// be read by controllers and templ components via the request context.
+//
+// Usage in templ components:
+//
+//	<meta name="csrf-token" content={ phoenix.CSRFToken(ctx) }/>
+type Assigns struct {
+	CurrentUser any               // authenticated user, set by middleware.LoadUser
+	CSRFToken   string            // set by middleware.CSRF
+	Flash       map[string]string // flash messages by kind, like "info" and "error"
+	Locale      string            // negotiated locale
+	values      map[string]any
+}
+
+// Set a custom value.
+func (a *Assigns) Set(key string, val any) {
+	if a.values == nil {
+		a.values = map[string]any{}
+	}
+	a.values[key] = val
+}
+
+// Get a custom value.
+func (a *Assigns) Get(key string) (any, bool) {
+	if a == nil {
+		return nil, false
+	}
+	val, ok := a.values[key]
+	return val, ok
+}
+
+// Return ctx with assigns.
+func WithAssigns(ctx context.Context, assigns *Assigns) context.Context {
+	return context.WithValue(ctx, assignsKey, assigns)
+}
+
+// Get assigns from ctx, it returns nil when there is no assigns.
+func GetAssigns(ctx context.Context) *Assigns {
+	assigns, _ := ctx.Value(assignsKey).(*Assigns)
+	return assigns
+}
+
+// Get assigns from ctx, a new one is created when there is no assigns.
+// The returned ctx always carry the assigns.
+func EnsureAssigns(ctx context.Context) (context.Context, *Assigns) {
+	if assigns := GetAssigns(ctx); assigns != nil {
+		return ctx, assigns
+	}
+	assigns := &Assigns{}
+	return WithAssigns(ctx, assigns), assigns
+}
+
+// Set a custom value into assigns of ctx, nothing happens when there is no
+// assigns.
+func Assign(ctx context.Context, key string, val any) {
+	if assigns := GetAssigns(ctx); assigns != nil {
+		assigns.Set(key, val)
+	}
+}
+
+// Get a custom value of type T from assigns of ctx.
+func Assigned[T any](ctx context.Context, key string) (T, bool) {
+	val, ok := GetAssigns(ctx).Get(key)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	t, ok := val.(T)
+	return t, ok
+}
+
+// Get current user from ctx, it is nil when no user is loaded.
+func CurrentUser(ctx context.Context) any {
+	if assigns := GetAssigns(ctx); assigns != nil {
+		return assigns.CurrentUser
+	}
+	return nil
+}
+
+// Get current user of type T from ctx.
+func CurrentUserAs[T any](ctx context.Context) (T, bool) {
+	user, ok := CurrentUser(ctx).(T)
+	return user, ok
+}
+
+// Get CSRF token from ctx, it should be put in forms as _csrf_token field or
+// in X-CSRF-Token header of ajax requests.
+func CSRFToken(ctx context.Context) string {
+	if assigns := GetAssigns(ctx); assigns != nil {
+		return assigns.CSRFToken
+	}
+	return ""
+}
+
+// Get flash messages from ctx.
+func Flash(ctx context.Context) map[string]string {
+	if assigns := GetAssigns(ctx); assigns != nil {
+		return assigns.Flash
+	}
+	return nil
+}
+
+// Get locale from ctx.
+func Locale(ctx context.Context) string {
+	if assigns := GetAssigns(ctx); assigns != nil {
+		return assigns.Locale
+	}
+	return ""
+}
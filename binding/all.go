@@ -0,0 +1,355 @@
+package binding
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-chi/chi/v5"
+)
+
+var (
+	ErrInvalidBody            = errors.New("invalid request body")
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+)
+
+func init() {
+	phoenix.RegisterError(ErrInvalidBody, http.StatusBadRequest, 0, "")
+	phoenix.RegisterError(ErrUnsupportedContentType, http.StatusUnsupportedMediaType, 0, "")
+}
+
+// Sources of BindAll, in order of precedence from low to high.
+var sources = []string{"query", "header", "cookie", "path"}
+
+// Bind obj from all parts of r, then validate it by the validator of gin.
+// Fields are filled by tags:
+//
+//	type UpdateUserReq struct {
+//		ID      int    `path:"id"`
+//		Version int    `query:"version,default=1"`
+//		Token   string `header:"X-Token"`
+//		Session string `cookie:"session"`
+//		Name    string `json:"name" form:"name" binding:"required"`
+//	}
+//
+// The body is decoded by Content-Type as json, xml or form. A value from
+// latter source overwrites former one: body < query < header < cookie < path,
+// so a client can never change the path id by body. A default is only used
+// when the field is still zero. Values are parsed as the form binder of gin,
+// so time_format, time_utc and time_location tags work for time.Time.
+//
+// Parse and validation errors are returned as phoenix.FieldError keyed by the
+// name on the wire, like "name" rather than "Name". A malformed body returns
+// ErrInvalidBody.
+func BindAll(r *http.Request, obj any) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding: %T is not a pointer to struct", obj)
+	}
+	if err := bindBody(r, obj); err != nil {
+		return err
+	}
+	ferr := phoenix.FieldError{}
+	for _, tag := range sources {
+		bindValues(rv.Elem(), tag, false, lookupFunc(r, tag), ferr)
+	}
+	if len(ferr) > 0 {
+		return ferr
+	}
+	return validate(obj)
+}
+
+// Bind all parts of r into T, see BindAll.
+func (b *Binder[T]) BindAll(r *http.Request) error {
+	return BindAll(r, (*T)(unsafe.Pointer(b)))
+}
+
+func bindBody(r *http.Request, obj any) error {
+	if r.Body == nil || r.Body == http.NoBody || r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return nil
+	}
+	var err error
+	switch filterFlags(requestHeader(r, "Content-Type")) {
+	case binding.MIMEJSON:
+		err = json.NewDecoder(r.Body).Decode(obj)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	case binding.MIMEXML, binding.MIMEXML2:
+		err = xml.NewDecoder(r.Body).Decode(obj)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	case binding.MIMEPOSTForm:
+		if err = r.ParseForm(); err == nil {
+			return bindForm(obj, r.PostForm)
+		}
+	case binding.MIMEMultipartPOSTForm:
+		if err = r.ParseMultipartForm(defaultMaxBytes); err == nil {
+			return bindForm(obj, r.MultipartForm.Value)
+		}
+	case "":
+		return nil
+	default:
+		return ErrUnsupportedContentType
+	}
+	return translateError(obj, err)
+}
+
+func bindForm(obj any, form map[string][]string) error {
+	ferr := phoenix.FieldError{}
+	bindValues(reflect.ValueOf(obj).Elem(), "form", true, formLookup(form), ferr)
+	if len(ferr) > 0 {
+		return ferr
+	}
+	return nil
+}
+
+// Values of name in a source, ok is false when it is absent.
+type lookup func(name string) (values []string, ok bool)
+
+func lookupFunc(r *http.Request, tag string) lookup {
+	switch tag {
+	case "query":
+		query := r.URL.Query()
+		return func(name string) ([]string, bool) {
+			vs, ok := query[name]
+			return vs, ok
+		}
+	case "header":
+		return func(name string) ([]string, bool) {
+			vs := r.Header.Values(name)
+			return vs, len(vs) > 0
+		}
+	case "cookie":
+		return func(name string) ([]string, bool) {
+			c, err := r.Cookie(name)
+			if err != nil {
+				return nil, false
+			}
+			return []string{c.Value}, true
+		}
+	default: // path
+		rctx := chi.RouteContext(r.Context())
+		return func(name string) ([]string, bool) {
+			if rctx == nil {
+				return nil, false
+			}
+			for i, k := range rctx.URLParams.Keys {
+				if k == name {
+					return []string{rctx.URLParams.Values[i]}, true
+				}
+			}
+			return nil, false
+		}
+	}
+}
+
+// Set fields of struct v which has tag, errors are put into ferr. When byName
+// is true, a field without tag is set by its name like gin does. It reports
+// whether any field is set.
+func bindValues(v reflect.Value, tag string, byName bool, get lookup, ferr phoenix.FieldError) (set bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf, fv := t.Field(i), v.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		nested := ft.Kind() == reflect.Struct && ft != timeType &&
+			!reflect.PointerTo(ft).Implements(textUnmarshalType)
+		value, ok := sf.Tag.Lookup(tag)
+		if !ok && (nested || !byName) {
+			// walk into embedded and nested structs
+			if !nested || !fv.CanSet() {
+				continue
+			}
+			if fv.Kind() != reflect.Pointer {
+				set = bindValues(fv, tag, byName, get, ferr) || set
+			} else if fv.IsNil() {
+				// keep nil unless something is set
+				nv := reflect.New(ft)
+				if bindValues(nv.Elem(), tag, byName, get, ferr) {
+					fv.Set(nv)
+					set = true
+				}
+			} else {
+				set = bindValues(fv.Elem(), tag, byName, get, ferr) || set
+			}
+			continue
+		}
+		if !fv.CanSet() {
+			continue
+		}
+		name, opts, _ := strings.Cut(value, ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		vals, ok := get(name)
+		if !ok {
+			def, has := strings.CutPrefix(opts, "default=")
+			if !has || !fv.IsZero() {
+				continue
+			}
+			vals = strings.Split(def, ";")
+		}
+		if err := setValue(fv, sf, vals); err != nil {
+			ft := indirectType(fv.Type())
+			if ft.Kind() == reflect.Slice && ft.Elem().Kind() != reflect.Uint8 {
+				ft = ft.Elem() // one of the values is wrong
+			}
+			ferr[name] = typeError(name, ft, err)
+		}
+		set = true
+	}
+	return
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	textUnmarshalType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// Set vals to v of field sf, a slice takes all values and others take the
+// first one. Values are parsed as gin does, time.Time by the time_format,
+// time_utc and time_location tags of sf, structs and maps by json.
+func setValue(v reflect.Value, sf reflect.StructField, vals []string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), sf, vals)
+	}
+	if v.Kind() == reflect.Slice && !reflect.PointerTo(v.Type()).Implements(textUnmarshalType) && v.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(v.Type(), len(vals), len(vals))
+		for i := range vals {
+			if err := setValue(s.Index(i), sf, vals[i:i+1]); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	var val string
+	if len(vals) > 0 {
+		val = vals[0]
+	}
+	if v.Type() == timeType {
+		return setTime(v, sf, val)
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(val))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(val)
+		if err == nil {
+			v.SetInt(int64(d))
+		}
+		return err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(val)
+	case reflect.Slice: // []byte
+		v.SetBytes([]byte(val))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Struct, reflect.Map:
+		return json.Unmarshal([]byte(val), v.Addr().Interface())
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Parse val into time v by tags of sf, the layout of time_format defaults to
+// RFC 3339, "unix" and "unixnano" are timestamps.
+//
+//	Birthday time.Time `form:"birthday" time_format:"2006-01-02" time_utc:"1"`
+func setTime(v reflect.Value, sf reflect.StructField, val string) error {
+	layout := sf.Tag.Get("time_format")
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	switch unit := strings.ToLower(layout); unit {
+	case "unix", "unixnano":
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return err
+		}
+		if unit == "unix" {
+			v.Set(reflect.ValueOf(time.Unix(n, 0)))
+		} else {
+			v.Set(reflect.ValueOf(time.Unix(0, n)))
+		}
+		return nil
+	}
+	if val == "" {
+		v.Set(reflect.ValueOf(time.Time{}))
+		return nil
+	}
+	loc := time.Local
+	if utc, _ := strconv.ParseBool(sf.Tag.Get("time_utc")); utc {
+		loc = time.UTC
+	}
+	if name := sf.Tag.Get("time_location"); name != "" {
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return err
+		}
+		loc = l
+	}
+	t, err := time.ParseInLocation(layout, val, loc)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(t))
+	return nil
+}
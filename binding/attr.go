@@ -0,0 +1,211 @@
+package binding
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-rel/changeset/params"
+	"github.com/ugorji/go/codec"
+	"gopkg.in/yaml.v3"
+)
+
+// Decode the body of r into params for changeset.
+type AttrDecoder func(r *http.Request) (params.Params, error)
+
+var (
+	attrMu       sync.RWMutex
+	attrDecoders = map[string]AttrDecoder{}
+)
+
+func init() {
+	RegisterAttrDecoder(binding.MIMEJSON, JsonAttr)
+	RegisterAttrDecoder(binding.MIMEPOSTForm, PostFromArrt)
+	RegisterAttrDecoder(binding.MIMEMultipartPOSTForm, MultipartFormAttr)
+	RegisterAttrDecoder(binding.MIMEXML, XmlAttr)
+	RegisterAttrDecoder(binding.MIMEXML2, XmlAttr)
+	RegisterAttrDecoder(binding.MIMEYAML, YamlAttr)
+	RegisterAttrDecoder("application/yaml", YamlAttr)
+	RegisterAttrDecoder("text/yaml", YamlAttr)
+	RegisterAttrDecoder(binding.MIMEMSGPACK, MsgpackAttr)
+	RegisterAttrDecoder(binding.MIMEMSGPACK2, MsgpackAttr)
+	RegisterAttrDecoder("application/vnd.msgpack", MsgpackAttr)
+}
+
+// Register decoder of contentType for Attr, it replaces the old one.
+//
+// Usage:
+//
+//	binding.RegisterAttrDecoder("application/cbor", func(r *http.Request) (params.Params, error) {
+//		var m map[string]any
+//		if err := cbor.NewDecoder(r.Body).Decode(&m); err != nil {
+//			return nil, err
+//		}
+//		return binding.MapAttr(m), nil
+//	})
+func RegisterAttrDecoder(contentType string, decoder AttrDecoder) {
+	attrMu.Lock()
+	defer attrMu.Unlock()
+	attrDecoders[strings.ToLower(contentType)] = decoder
+}
+
+func attrDecoder(contentType string) (AttrDecoder, bool) {
+	attrMu.RLock()
+	defer attrMu.RUnlock()
+	decoder, ok := attrDecoders[strings.ToLower(contentType)]
+	return decoder, ok
+}
+
+// Convert a decoded map into params. Nested []any of maps are converted, so
+// changeset can cast them as has many associations.
+func MapAttr(m map[string]any) params.Params {
+	return params.Map(normalize(m).(map[string]any))
+}
+
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []any:
+		maps := make([]map[string]any, 0, len(v))
+		for i, e := range v {
+			v[i] = normalize(e)
+			if m, ok := v[i].(map[string]any); ok {
+				maps = append(maps, m)
+			}
+		}
+		if len(v) > 0 && len(maps) == len(v) {
+			return maps
+		}
+		return v
+	case []byte: // msgpack bin
+		return string(v)
+	}
+	return v
+}
+
+func XmlAttr(r *http.Request) (params.Params, error) {
+	if r == nil || r.Body == nil {
+		return nil, errors.New("invalid request")
+	}
+	m, err := decodeXML(xml.NewDecoder(r.Body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBody, err)
+	}
+	return MapAttr(m), nil
+}
+
+// Decode children of the root element into map. An element with children is
+// a map, repeated elements are a slice and others are text.
+//
+//	<user><name>a</name><tags>x</tags><tags>y</tags></user>
+//	{"name": "a", "tags": ["x", "y"]}
+func decodeXML(d *xml.Decoder) (map[string]any, error) {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return map[string]any{}, nil
+			}
+			return nil, err
+		}
+		if _, ok := tok.(xml.StartElement); ok {
+			v, err := decodeElement(d)
+			if err != nil {
+				return nil, err
+			}
+			if m, ok := v.(map[string]any); ok {
+				return m, nil
+			}
+			return map[string]any{}, nil
+		}
+	}
+}
+
+// Decode the element just started, until its end.
+func decodeElement(d *xml.Decoder) (any, error) {
+	var (
+		text     strings.Builder
+		children map[string]any
+	)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			v, err := decodeElement(d)
+			if err != nil {
+				return nil, err
+			}
+			if children == nil {
+				children = map[string]any{}
+			}
+			name := t.Name.Local
+			switch old := children[name].(type) {
+			case nil:
+				children[name] = v
+			case []any:
+				children[name] = append(old, v)
+			default:
+				children[name] = []any{old, v}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if children != nil {
+				return children, nil
+			}
+			return strings.TrimSpace(text.String()), nil
+		}
+	}
+}
+
+func YamlAttr(r *http.Request) (params.Params, error) {
+	if r == nil || r.Body == nil {
+		return nil, errors.New("invalid request")
+	}
+	m := map[string]any{}
+	if err := yaml.NewDecoder(r.Body).Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBody, err)
+	}
+	return MapAttr(m), nil
+}
+
+func MsgpackAttr(r *http.Request) (params.Params, error) {
+	if r == nil || r.Body == nil {
+		return nil, errors.New("invalid request")
+	}
+	h := &codec.MsgpackHandle{}
+	h.RawToString = true
+	h.MapType = reflect.TypeOf(map[string]any(nil))
+	m := map[string]any{}
+	if err := codec.NewDecoder(r.Body, h).Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBody, err)
+	}
+	return MapAttr(m), nil
+}
+
+func validJSON(bs []byte) error {
+	if len(strings.TrimSpace(string(bs))) == 0 || json.Valid(bs) {
+		return nil
+	}
+	return ErrInvalidBody
+}
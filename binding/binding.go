@@ -9,6 +9,7 @@ import (
 	"unsafe"
 
 	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/upload"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-rel/changeset/params"
 )
@@ -28,50 +29,49 @@ const (
 //	var req UserReq
 //	err := req.Bind(r)
 //
-// The binding implementation is provide by github.com/gin-gonic/gin.
+// The binding implementation is provide by github.com/gin-gonic/gin. Errors
+// are returned as phoenix.FieldError keyed by wire names, see Bind.
 type Binder[T any] struct {
 }
 
 // Bind data fot T from http.Request
 func (b *Binder[T]) Bind(r *http.Request) error {
-	return binding.
-		Default(r.Method, filterFlags(requestHeader(r, "Content-Type"))).
-		Bind(r, (*T)(unsafe.Pointer(b)))
+	return Bind(r, (*T)(unsafe.Pointer(b)))
 }
 
 // Bind header data for T from http.Request
 func (b *Binder[T]) BindHeader(r *http.Request) error {
-	return binding.Header.Bind(r, (*T)(unsafe.Pointer(b)))
+	return BindHeader(r, (*T)(unsafe.Pointer(b)))
 }
 
 // Bind query data for T from http.Request
 func (b *Binder[T]) BindQuery(r *http.Request) error {
-	return binding.Query.Bind(r, (*T)(unsafe.Pointer(b)))
+	return BindQuery(r, (*T)(unsafe.Pointer(b)))
 }
 
 // Bind post form data for T from http.Request
 func (b *Binder[T]) BindPost(r *http.Request) error {
-	return binding.FormPost.Bind(r, (*T)(unsafe.Pointer(b)))
+	return BindPost(r, (*T)(unsafe.Pointer(b)))
 }
 
 // Bind form data for T from http.Request
 func (b *Binder[T]) BindForm(r *http.Request) error {
-	return binding.Form.Bind(r, (*T)(unsafe.Pointer(b)))
+	return BindForm(r, (*T)(unsafe.Pointer(b)))
 }
 
 // Bind json data for T from http.Request
 func (b *Binder[T]) BindJSON(r *http.Request) error {
-	return binding.JSON.Bind(r, (*T)(unsafe.Pointer(b)))
+	return BindJSON(r, (*T)(unsafe.Pointer(b)))
 }
 
 // Bind xml data for T from http.Request
 func (b *Binder[T]) BindXML(r *http.Request) error {
-	return binding.XML.Bind(r, (*T)(unsafe.Pointer(b)))
+	return BindXML(r, (*T)(unsafe.Pointer(b)))
 }
 
 // Bind yaml data for T from http.Request
 func (b *Binder[T]) BindYAML(r *http.Request) error {
-	return binding.YAML.Bind(r, (*T)(unsafe.Pointer(b)))
+	return BindYAML(r, (*T)(unsafe.Pointer(b)))
 }
 
 func filterFlags(content string) string {
@@ -87,38 +87,70 @@ func requestHeader(r *http.Request, key string) string {
 	return r.Header.Get(key)
 }
 
+// Bind obj from r by method and Content-Type, then validate it. Validation
+// errors, type mismatches and form parse errors are returned as
+// phoenix.FieldError keyed by the json or form name, each error is a
+// phoenix.ValidationError:
+//
+//	{"name": {"code": "required", "message": "name is required"}}
+//	{"age": {"code": "type", "message": "age must be integer", "params": {"type": "integer"}}}
+//
+// A malformed body returns ErrInvalidBody.
 func Bind(r *http.Request, obj any) error {
-	return binding.
-		Default(r.Method, filterFlags(requestHeader(r, "Content-Type"))).
-		Bind(r, obj)
+	switch b := binding.Default(r.Method, filterFlags(requestHeader(r, "Content-Type"))); b {
+	case binding.Form:
+		return BindForm(r, obj)
+	case binding.FormPost:
+		return BindPost(r, obj)
+	case binding.FormMultipart:
+		err := b.Bind(r, obj)
+		if r.MultipartForm == nil {
+			return translateError(obj, err)
+		}
+		return translateMapping(obj, err, "form", formLookup(r.MultipartForm.Value))
+	default:
+		return translateError(obj, b.Bind(r, obj))
+	}
 }
 
 func BindHeader(r *http.Request, obj any) error {
-	return binding.Header.Bind(r, obj)
+	return translateMapping(obj, binding.Header.Bind(r, obj), "header", func(name string) ([]string, bool) {
+		vs := r.Header.Values(name)
+		return vs, len(vs) > 0
+	})
 }
 
 func BindQuery(r *http.Request, obj any) error {
-	return binding.Query.Bind(r, obj)
+	return translateMapping(obj, binding.Query.Bind(r, obj), "form", formLookup(r.URL.Query()))
 }
 
 func BindPost(r *http.Request, obj any) error {
-	return binding.FormPost.Bind(r, obj)
+	err := binding.FormPost.Bind(r, obj)
+	return translateMapping(obj, err, "form", formLookup(r.PostForm))
 }
 
 func BindForm(r *http.Request, obj any) error {
-	return binding.Form.Bind(r, obj)
+	err := binding.Form.Bind(r, obj)
+	return translateMapping(obj, err, "form", formLookup(r.Form))
 }
 
 func BindJSON(r *http.Request, obj any) error {
-	return binding.JSON.Bind(r, obj)
+	return translateError(obj, binding.JSON.Bind(r, obj))
 }
 
 func BindXML(r *http.Request, obj any) error {
-	return binding.XML.Bind(r, obj)
+	return translateError(obj, binding.XML.Bind(r, obj))
 }
 
 func BindYAML(r *http.Request, obj any) error {
-	return binding.YAML.Bind(r, obj)
+	return translateError(obj, binding.YAML.Bind(r, obj))
+}
+
+func formLookup(form map[string][]string) lookup {
+	return func(name string) ([]string, bool) {
+		vs, ok := form[name]
+		return vs, ok
+	}
 }
 
 // Save file from http.Request.
@@ -131,6 +163,8 @@ func BindYAML(r *http.Request, obj any) error {
 // by Name("a.txt").
 // If you want to replace existed file, you can use Replace option.
 //
+// For multiple files, size limits and other storages, see package upload.
+//
 // Usage:
 //
 //	BindFile(r, Form("my-file"), Name("a.txt"), Dir("/data"), Replace)
@@ -143,7 +177,9 @@ func BindFile(r *http.Request, opts ...option) (int64, error) {
 		opts[i](&meta)
 	}
 
-	r.ParseMultipartForm(defaultMaxBytes)
+	if err := r.ParseMultipartForm(defaultMaxBytes); err != nil {
+		return 0, err
+	}
 	src, header, err := r.FormFile(meta.formFileName)
 	if err != nil {
 		return 0, err
@@ -151,7 +187,10 @@ func BindFile(r *http.Request, opts ...option) (int64, error) {
 	defer src.Close()
 
 	if meta.destFileName == "" {
-		meta.destFileName = header.Filename
+		meta.destFileName = upload.Sanitize(header.Filename)
+		if meta.destFileName == "" {
+			return 0, upload.ErrInvalidFilename
+		}
 	}
 
 	destFile := filepath.Join(meta.destPath, meta.destFileName)
@@ -206,23 +245,30 @@ func Replace(fm *fileMeta) {
 
 func exist(path string) bool {
 	_, err := os.Stat(path)
-	return errors.Is(err, os.ErrNotExist)
+	return err == nil
 }
 
+// Parse params for changeset from r. The query is used for GET, otherwise the
+// body is decoded by its Content-Type: json, form, multipart form, xml, yaml
+// and msgpack are supported, others can be added by RegisterAttrDecoder. A
+// body without Content-Type is parsed as form.
+//
+// Nested keys and arrays of forms are supported:
+//
+//	user[name]=a&user[address][city]=b&tags[]=x&tags[]=y&items[0][name]=c
 func Attr(r *http.Request) (params.Params, error) {
 	if r.Method == http.MethodGet {
 		return FormAttr(r)
 	}
 	contentType := filterFlags(requestHeader(r, "Content-Type"))
-	// TODO: support more content type
-	switch contentType {
-	case binding.MIMEJSON:
-		return JsonAttr(r)
-	case binding.MIMEMultipartPOSTForm:
-		return MultipartFormAttr(r)
-	default:
+	if contentType == "" {
 		return FormAttr(r)
 	}
+	decoder, ok := attrDecoder(contentType)
+	if !ok {
+		return nil, ErrUnsupportedContentType
+	}
+	return decoder(r)
 }
 
 func FormAttr(r *http.Request) (params.Params, error) {
@@ -261,10 +307,14 @@ func JsonAttr(r *http.Request) (params.Params, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err = validJSON(bs); err != nil {
+		return nil, err
+	}
 	return params.ParseJSON(string(bs)), nil
 }
 
-// Get ID form r.Context() by key, the ID is int type.
+// Get ID form r.Context() by key, the ID is int type. It panics when the ID is
+// missing, phoenix.IntID.Get does not.
 func ContextID(r *http.Request) int {
 	return r.Context().Value(phoenix.ID).(int)
 }
@@ -294,7 +344,8 @@ func ContextString(r *http.Request, key string) string {
 	return r.Context().Value(phoenix.CtxKey(key)).(string)
 }
 
-// Get value from r.Context() by key.
+// Get value from r.Context() by key. It panics when the value is missing or
+// not T, use phoenix.Key[T] for a safe one.
 func ContextVal[T any](r *http.Request, key string) T {
 	return r.Context().Value(phoenix.CtxKey(key)).(T)
 }
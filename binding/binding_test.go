@@ -0,0 +1,180 @@
+package binding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DOVECYJ/phoenix"
+	ginbinding "github.com/gin-gonic/gin/binding"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type updateReq struct {
+	ID      int    `path:"id"`
+	Version int    `query:"version,default=1"`
+	Page    int    `query:"page,default=1"`
+	Token   string `header:"X-Token"`
+	Session string `cookie:"session"`
+	Name    string `json:"name" form:"name" binding:"required"`
+	Age     int    `json:"age" form:"age" binding:"min=18"`
+}
+
+func newRequest(method, target, contentType, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+func withPath(r *http.Request, key, value string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add(key, value)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestBindAll(t *testing.T) {
+	r := newRequest("PUT", "/users/7?version=3&id=8", "application/json", `{"name":"bob","age":20}`)
+	r.Header.Set("X-Token", "t")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "s"})
+	r = withPath(r, "id", "7")
+
+	var req updateReq
+	req.Page = 5 // default is only used for zero
+	if err := BindAll(r, &req); err != nil {
+		t.Fatal(err)
+	}
+	want := updateReq{ID: 7, Version: 3, Page: 5, Token: "t", Session: "s", Name: "bob", Age: 20}
+	if req != want {
+		t.Errorf("got %+v, want %+v", req, want)
+	}
+
+	// query and path overwrite body
+	var form struct {
+		ID   int    `form:"id" path:"id"`
+		Name string `form:"name" query:"name"`
+	}
+	r = withPath(newRequest("POST", "/?name=q", "application/x-www-form-urlencoded", "id=2&name=bob"), "id", "1")
+	if err := BindAll(r, &form); err != nil || form.ID != 1 || form.Name != "q" {
+		t.Errorf("got %+v, %v", form, err)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		r     *http.Request
+		bind  func(*http.Request, any) error
+		codes map[string]string // field to code, nil for a non field error
+		err   error
+	}{
+		{"required and min", newRequest("POST", "/", "application/json", `{"age":3}`), BindAll,
+			map[string]string{"name": "required", "age": "min"}, nil},
+		{"json type", newRequest("POST", "/", "application/json", `{"name":"a","age":"x"}`), BindAll,
+			map[string]string{"age": "type"}, nil},
+		{"query type", newRequest("GET", "/?version=x", "", ""), BindAll,
+			map[string]string{"version": "type"}, nil},
+		{"form type", newRequest("POST", "/", "application/x-www-form-urlencoded", "name=a&age=x"), Bind,
+			map[string]string{"age": "type"}, nil},
+		{"form required", newRequest("POST", "/", "application/x-www-form-urlencoded", "age=20"), Bind,
+			map[string]string{"name": "required"}, nil},
+		{"malformed json", newRequest("POST", "/", "application/json", `{"name":`), Bind, nil, ErrInvalidBody},
+		{"unsupported", newRequest("POST", "/", "text/csv", "a,b"), BindAll, nil, ErrUnsupportedContentType},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.bind(c.r, &updateReq{})
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Fatalf("err = %v, want %v", err, c.err)
+				}
+				return
+			}
+			var ferr phoenix.FieldError
+			if !errors.As(err, &ferr) || len(ferr) != len(c.codes) {
+				t.Fatalf("err = %#v, want fields %v", err, c.codes)
+			}
+			for field, code := range c.codes {
+				var verr phoenix.ValidationError
+				if !errors.As(ferr[field], &verr) || verr.Code != code {
+					t.Errorf("%s: got %#v, want code %s", field, ferr[field], code)
+				}
+			}
+		})
+	}
+}
+
+func TestBindQuery(t *testing.T) {
+	var req struct {
+		Day    time.Time         `form:"day" time_format:"2006-01-02" time_utc:"1"`
+		At     time.Time         `form:"at" time_format:"unix"`
+		Tags   []string          `form:"tags"`
+		Filter map[string]string `form:"filter"`
+		Limit  int               `form:"limit,default=10"`
+	}
+	q := url.Values{"day": {"2024-03-01"}, "at": {"1700000000"}, "tags": {"a", "b"}, "filter": {`{"name":"bob"}`}}
+	if err := BindQuery(newRequest("GET", "/?"+q.Encode(), "", ""), &req); err != nil {
+		t.Fatal(err)
+	}
+	if !req.Day.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) || req.At.Unix() != 1700000000 ||
+		len(req.Tags) != 2 || req.Filter["name"] != "bob" || req.Limit != 10 {
+		t.Errorf("got %+v", req)
+	}
+
+	err := BindQuery(newRequest("GET", "/?day=03-01", "", ""), &req)
+	var ferr phoenix.FieldError
+	if !errors.As(err, &ferr) || ferr["day"] == nil {
+		t.Errorf("err = %v, want error of day", err)
+	}
+}
+
+func TestAttr(t *testing.T) {
+	cases := []struct {
+		contentType, body string
+	}{
+		{"application/json", `{"user":{"name":"bob","tags":["a"]}}`},
+		{"application/x-www-form-urlencoded", "user[name]=bob&user[tags][]=a"},
+		{"application/xml", `<root><user><name>bob</name><tags>a</tags></user></root>`},
+		{"application/yaml", "user:\n  name: bob\n  tags: [a]\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.contentType, func(t *testing.T) {
+			p, err := Attr(newRequest("POST", "/", c.contentType, c.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			user, ok := p.GetParams("user")
+			if !ok || !strings.Contains(fmt.Sprint(user.Get("name")), "bob") || !user.Exists("tags") {
+				t.Errorf("got %#v", p)
+			}
+		})
+	}
+	if _, err := Attr(newRequest("POST", "/", "text/csv", "a,b")); !errors.Is(err, ErrUnsupportedContentType) {
+		t.Errorf("err = %v, want %v", err, ErrUnsupportedContentType)
+	}
+}
+
+func TestValidatorNames(t *testing.T) {
+	var req struct {
+		FullName string `json:"full_name" binding:"required"`
+	}
+	err := BindAll(newRequest("POST", "/", "application/json", `{}`), &req)
+	var ferr phoenix.FieldError
+	if !errors.As(err, &ferr) || !strings.Contains(ferr["full_name"].Error(), "full_name") {
+		t.Errorf("err = %v, want message of full_name", err)
+	}
+
+	// the shared validator of gin is not changed
+	var verr validator.ValidationErrors
+	if err := ginbinding.Validator.ValidateStruct(&req); !errors.As(err, &verr) || verr[0].Field() != "FullName" {
+		t.Errorf("gin validator reports %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/i18n"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Validate obj by the validator of gin, validation errors are converted
+// into phoenix.FieldError keyed by wire names.
+func validate(obj any) error {
+	if binding.Validator == nil {
+		return nil
+	}
+	return translateError(obj, binding.Validator.ValidateStruct(obj))
+}
+
+// Convert errors of binding obj into phoenix.FieldError keyed by wire names,
+// each error is a phoenix.ValidationError with code and params:
+//
+//	validator errors: code is the tag like required and min
+//	type mismatch:    code is type, params has the expected type
+//
+// Other decoding errors are wrapped by ErrInvalidBody.
+func translateError(obj any, err error) error {
+	if err == nil {
+		return nil
+	}
+	var (
+		ferr    phoenix.FieldError
+		verr    validator.ValidationErrors
+		typeErr *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &ferr), errors.Is(err, ErrInvalidBody), errors.Is(err, ErrUnsupportedContentType):
+		return err
+	case errors.As(err, &verr):
+		t := reflect.TypeOf(obj)
+		ferr = make(phoenix.FieldError, len(verr))
+		for _, fe := range verr {
+			name := wireName(t, fe.StructNamespace())
+			ferr[name] = i18n.Default.ValidationError("", wireFieldError{fe, name[strings.LastIndexByte(name, '.')+1:]})
+		}
+		return ferr
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return phoenix.FieldError{typeErr.Field: typeError(typeErr.Field, typeErr.Type, err)}
+	default:
+		return fmt.Errorf("%w: %v", ErrInvalidBody, err)
+	}
+}
+
+// A validator error reporting the wire name of field, the validator of gin is
+// shared by the application, so its tag name func is not touched.
+type wireFieldError struct {
+	validator.FieldError
+	name string
+}
+
+func (e wireFieldError) Field() string {
+	return e.name
+}
+
+// Translate err of a gin binder which maps values from a source of tag. gin
+// does not tell which field fails to map, so it's found by mapping the source
+// into a new obj, then reported on the field like a type mismatch.
+func translateMapping(obj any, err error, tag string, get lookup) error {
+	var verr validator.ValidationErrors
+	if err == nil || errors.As(err, &verr) {
+		return translateError(obj, err)
+	}
+	if t := reflect.TypeOf(obj); t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct {
+		ferr := phoenix.FieldError{}
+		if bindValues(reflect.New(t.Elem()).Elem(), tag, true, get, ferr); len(ferr) > 0 {
+			return ferr
+		}
+	}
+	return translateError(obj, err)
+}
+
+// A type mismatch error of field, t is the type expected.
+func typeError(field string, t reflect.Type, err error) error {
+	name := typeName(t)
+	return phoenix.ValidationError{
+		Code:    "type",
+		Message: i18n.Translate("", "validation.type", "field", field, "type", name),
+		Params:  map[string]any{"type": name},
+		Err:     err,
+	}
+}
+
+func typeName(t reflect.Type) string {
+	t = indirectType(t)
+	switch t {
+	case timeType:
+		return "time"
+	case durationType:
+		return "duration"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
+
+// Convert struct namespace like "UserReq.Address.City" into the name on the
+// wire like "address.city". Tags are tried in order of json, form, query,
+// path, header and cookie.
+func wireName(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")
+	names := make([]string, 0, len(segments))
+	t = indirectType(t)
+	if segments[0] == t.Name() {
+		// namespace of an anonymous struct has no type name
+		segments = segments[1:]
+	}
+	for _, seg := range segments {
+		fieldName, index, _ := strings.Cut(seg, "[")
+		if index != "" {
+			index = "[" + index
+		}
+		if t.Kind() != reflect.Struct {
+			names = append(names, seg)
+			continue
+		}
+		sf, ok := t.FieldByName(fieldName)
+		if !ok {
+			names = append(names, seg)
+			continue
+		}
+		t = indirectType(sf.Type)
+		for t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = indirectType(t.Elem())
+		}
+		name, named := tagName(sf)
+		if sf.Anonymous && !named {
+			continue
+		}
+		names = append(names, name+index)
+	}
+	return strings.Join(names, ".")
+}
+
+func tagName(sf reflect.StructField) (string, bool) {
+	for _, tag := range []string{"json", "form", "query", "path", "header", "cookie"} {
+		if value, ok := sf.Tag.Lookup(tag); ok {
+			if name, _, _ := strings.Cut(value, ","); name != "" && name != "-" {
+				return name, true
+			}
+		}
+	}
+	return sf.Name, false
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/DOVECYJ/phoenix/router"
+	"github.com/andybalholm/brotli"
+	"github.com/urfave/cli/v2"
+)
+
+// Files of these types are compressed, others like images are compressed
+// already.
+var compressible = []string{".css", ".js", ".mjs", ".map", ".json", ".svg", ".html", ".htm", ".txt", ".xml", ".ico", ".wasm", ".ttf", ".otf", ".eot"}
+
+// A digested name like app-0123456789abcdef0123456789abcdef.css.
+var digestedName = regexp.MustCompile(`-[0-9a-f]{32}(\.[^./]+)?$`)
+
+// The params for digest static files.
+type digestParam struct {
+	Dir   string `validate:"required"` // static directory
+	Clean bool   `validate:"-"`        // remove digested files
+}
+
+func (p *digestParam) bind(ctx *cli.Context, args ...string) {
+	p.Dir = ctx.String("dir")
+	p.Clean = ctx.Bool("clean")
+}
+
+// Write a copy with content hash in its name for each static file, gzip and
+// brotli variants of them, and the manifest. Old digested files are kept, so
+// pages of the last deploy still work.
+func (p *digestParam) digest() error {
+	if p.Clean {
+		return p.clean()
+	}
+	m := p.readManifest()
+	m.Version = 1
+	m.Latest = map[string]string{}
+	if m.Digests == nil {
+		m.Digests = map[string]router.Digest{}
+	}
+	err := filepath.WalkDir(p.Dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		logical, err := filepath.Rel(p.Dir, name)
+		if err != nil {
+			return err
+		}
+		logical = filepath.ToSlash(logical)
+		if p.generated(logical, m) {
+			return nil
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		sum := md5.Sum(data)
+		digest := hex.EncodeToString(sum[:])
+		ext := path.Ext(logical)
+		digested := strings.TrimSuffix(logical, ext) + "-" + digest + ext
+		if err = os.WriteFile(filepath.Join(p.Dir, digested), data, 0644); err != nil {
+			return err
+		}
+		m.Latest[logical] = digested
+		m.Digests[digested] = router.Digest{LogicalPath: logical, Digest: digest, Size: info.Size(), Mtime: info.ModTime().Unix()}
+		fmt.Printf("* digest: %s -> %s\n", logical, digested)
+		if !compressibleFile(logical) {
+			return nil
+		}
+		for _, file := range []string{logical, digested} {
+			if err = compress(filepath.Join(p.Dir, file), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(p.Dir, router.ManifestName)
+	fmt.Println("* create:", name)
+	return os.WriteFile(name, data, 0644)
+}
+
+// Remove digested files, compressed variants and the manifest.
+func (p *digestParam) clean() error {
+	m := p.readManifest()
+	return filepath.WalkDir(p.Dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		logical, err := filepath.Rel(p.Dir, name)
+		if err != nil {
+			return err
+		}
+		if !p.generated(filepath.ToSlash(logical), m) {
+			return nil
+		}
+		fmt.Println("- removed:", name)
+		return os.Remove(name)
+	})
+}
+
+func (p *digestParam) readManifest() router.Manifest {
+	var m router.Manifest
+	if data, err := os.ReadFile(filepath.Join(p.Dir, router.ManifestName)); err == nil {
+		json.Unmarshal(data, &m)
+	}
+	return m
+}
+
+// Whether the file is written by digest.
+func (p *digestParam) generated(logical string, m router.Manifest) bool {
+	if logical == router.ManifestName || strings.HasSuffix(logical, ".gz") || strings.HasSuffix(logical, ".br") {
+		return true
+	}
+	if _, ok := m.Digests[logical]; ok {
+		return true
+	}
+	return digestedName.MatchString(logical)
+}
+
+func compressibleFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	for _, e := range compressible {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// Write gzip and brotli variants of name, a variant not smaller than data is
+// not written.
+func compress(name string, data []byte) error {
+	var gz bytes.Buffer
+	gw, _ := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+	if err := write(gw, data); err != nil {
+		return err
+	}
+	var br bytes.Buffer
+	if err := write(brotli.NewWriterLevel(&br, brotli.BestCompression), data); err != nil {
+		return err
+	}
+	for ext, buf := range map[string]*bytes.Buffer{".gz": &gz, ".br": &br} {
+		if buf.Len() >= len(data) {
+			continue
+		}
+		if err := os.WriteFile(name+ext, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func write(w io.WriteCloser, data []byte) error {
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/urfave/cli/v2"
+)
+
+// Match the key of i18n.T(ctx, "key") and i18n.Translate(locale, "key").
+var localeKeyPattern = regexp.MustCompile("i18n\\.(?:T|Translate)\\(\\s*[^,()]+,\\s*(\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`)")
+
+// The params for extract message keys into catalogs.
+type localeParam struct {
+	Dir     string   `validate:"required"` // catalog directory
+	Locales []string `validate:"-"`        // locales to extract
+	Source  string   `validate:"required"` // source directory
+}
+
+func (p *localeParam) bind(ctx *cli.Context, args ...string) {
+	p.Dir = ctx.String("dir")
+	p.Source = ctx.String("source")
+	p.Locales = ctx.StringSlice("locale")
+}
+
+// Extract message keys in source and add the missing ones into each catalog
+// with empty translation. Existing translations are kept.
+func (p *localeParam) extract() error {
+	keys, err := extractLocaleKeys(p.Source)
+	if err != nil {
+		return err
+	}
+	locales := p.Locales
+	if len(locales) == 0 {
+		// update existing catalogs
+		files, _ := filepath.Glob(filepath.Join(p.Dir, "*.toml"))
+		jsons, _ := filepath.Glob(filepath.Join(p.Dir, "*.json"))
+		for _, f := range append(files, jsons...) {
+			locales = append(locales, filepath.Base(f))
+		}
+	}
+	if len(locales) == 0 {
+		return errors.New("no catalog found, please specify locales with --locale")
+	}
+	if err := os.MkdirAll(p.Dir, os.ModePerm); err != nil {
+		return err
+	}
+	for _, locale := range locales {
+		name := filepath.Join(p.Dir, locale)
+		if filepath.Ext(name) == "" {
+			name += ".toml"
+		}
+		n, err := mergeCatalog(name, keys)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("* update: %s (%d new keys)\n", name, n)
+	}
+	return nil
+}
+
+// Find message keys in .go and .templ files of dir, generated _templ.go files
+// are skipped.
+func extractLocaleKeys(dir string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if strings.HasSuffix(path, "_templ.go") ||
+			(!strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, ".templ")) {
+			return nil
+		}
+		bs, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range localeKeyPattern.FindAllSubmatch(bs, -1) {
+			key, err := strconv.Unquote(string(match[1]))
+			if err != nil {
+				fmt.Printf("! skipped: %s %s\n", path, match[1])
+				continue
+			}
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	slices.Sort(keys)
+	return slices.Compact(keys), err
+}
+
+// Add missing keys into catalog file name, it returns count of added keys.
+func mergeCatalog(name string, keys []string) (int, error) {
+	bs, err := os.ReadFile(name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	messages := map[string]any{}
+	isJSON := filepath.Ext(name) == ".json"
+	if isJSON {
+		err = json.Unmarshal(bs, &messages)
+	} else {
+		err = toml.Unmarshal(bs, &messages)
+	}
+	if len(bs) > 0 && err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	exist := map[string]bool{}
+	flattenKeys(exist, "", messages)
+	missing := map[string]any{}
+	for _, key := range keys {
+		if !exist[key] {
+			missing[key] = ""
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+
+	if isJSON {
+		for k, v := range missing {
+			messages[k] = v
+		}
+		bs, err = json.MarshalIndent(messages, "", "  ")
+		if err != nil {
+			return 0, err
+		}
+		return len(missing), os.WriteFile(name, append(bs, '\n'), 0644)
+	}
+	// top level keys must be put before the first table, so comments and
+	// order of the file are kept
+	block, err := toml.Marshal(missing)
+	if err != nil {
+		return 0, err
+	}
+	if len(bs) == 0 {
+		bs = []byte("# Translations, a key without translation falls back to the default locale.\n\n")
+	}
+	i := tableHeader.FindIndex(bs)
+	if i == nil {
+		bs = append(bytes.TrimRight(bs, "\n"), '\n')
+		bs = append(bs, block...)
+	} else {
+		rest := append(append(block, '\n'), bs[i[0]:]...)
+		bs = append(bs[:i[0]:i[0]], rest...)
+	}
+	return len(missing), os.WriteFile(name, bs, 0644)
+}
+
+var tableHeader = regexp.MustCompile(`(?m)^\[`)
+
+func flattenKeys(keys map[string]bool, prefix string, messages map[string]any) {
+	for k, v := range messages {
+		if m, ok := v.(map[string]any); ok {
+			if _, plural := m["other"]; !plural {
+				flattenKeys(keys, prefix+k+".", m)
+				continue
+			}
+		}
+		keys[prefix+k] = true
+	}
+}
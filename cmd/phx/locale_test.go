@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+func TestMergeCatalog(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "lib")
+	os.MkdirAll(src, os.ModePerm)
+	os.WriteFile(filepath.Join(src, "page.templ"), []byte(`<h1>{ i18n.T(ctx, "Listing users") }</h1>
+<p>{ i18n.T(ctx, "%{count} users", "count", n) }</p>
+<p>{ i18n.Translate(locale, `+"`users.title`"+`) }</p>`), 0o644)
+	os.WriteFile(filepath.Join(src, "page_templ.go"), []byte(`i18n.T(ctx, "generated")`), 0o644)
+
+	keys, err := extractLocaleKeys(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(keys, "|") != "%{count} users|Listing users|users.title" {
+		t.Fatalf("keys = %q", keys)
+	}
+
+	name := filepath.Join(dir, "zh.toml")
+	catalog := `# my comment
+"Listing users" = "用户列表"
+"%{count} users" = { other = "%{count} 个用户" }
+
+[users]
+name = "姓名"
+`
+	os.WriteFile(name, []byte(catalog), 0o644)
+	n, err := mergeCatalog(name, append(keys, "users.name", "users.email"))
+	if err != nil || n != 2 {
+		t.Fatalf("merge: %d, %v", n, err)
+	}
+	bs, _ := os.ReadFile(name)
+	if !strings.HasPrefix(string(bs), "# my comment\n") || !strings.Contains(string(bs), "[users]\nname = \"姓名\"") {
+		t.Errorf("comments or tables are not kept:\n%s", bs)
+	}
+	var messages map[string]any
+	if err := toml.Unmarshal(bs, &messages); err != nil {
+		t.Fatalf("invalid toml: %v\n%s", err, bs)
+	}
+	got := map[string]bool{}
+	flattenKeys(got, "", messages)
+	for _, key := range []string{"Listing users", "%{count} users", "users.name", "users.title", "users.email"} {
+		if !got[key] {
+			t.Errorf("key %q is missing:\n%s", key, bs)
+		}
+	}
+
+	// nothing to add
+	if n, err := mergeCatalog(name, keys); err != nil || n != 0 {
+		t.Errorf("merge again: %d, %v", n, err)
+	}
+}
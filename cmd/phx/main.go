@@ -27,6 +27,10 @@ var (
 //	phx gen.context user User --app hello
 //	phx gen.html user User --table users --fields Name:string --app hello
 //	phx gen.api user User --table users --fields Name:string --app hello
+//	phx gen.locale --locale zh-CN --locale en
+//	phx openapi --output openapi.json
+//	phx routes --path /users --method GET
+//	phx digest --dir assets
 //	phx build
 //	phx run
 //	phx migrate
@@ -184,9 +188,9 @@ func main() {
 					if err := cmd.Cmd("templ generate").Run(); err != nil {
 						return err
 					}
-					fmt.Printf("\nAdd the resource to your router in\n"+
+					fmt.Printf("\nAdd the resource to the browser scope of your router in\n"+
 						"lib/%s_web/router.go:\n\n\t"+
-						"root.Route(\"/%s\", router.Resource(controllers.%sController{}))\n",
+						"r.Route(\"/%s\", router.Resource(controllers.%sController{}))\n",
 						controllerParam.App, controllerParam.Path, controllerParam.Entity)
 					if !migrateParam.created() {
 						fmt.Printf("\nAdd the migration to your migrate in\n"+
@@ -300,6 +304,133 @@ func main() {
 					return nil
 				},
 			},
+			{ // extract i18n message keys
+				Name:  "gen.locale",
+				Usage: "extract i18n message keys into catalogs",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "locale",
+						Usage: "locales to extract, default is existing catalogs",
+						Value: nil,
+					},
+					&cli.StringFlag{
+						Name:  "dir",
+						Usage: "catalog directory",
+						Value: "priv/locales",
+					},
+					&cli.StringFlag{
+						Name:  "source",
+						Usage: "source directory",
+						Value: "lib",
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					p := new(localeParam)
+					if err := bindAndValide(ctx, p); err != nil {
+						return err
+					}
+					return p.extract()
+				},
+			},
+			{ // export openapi document
+				Name:  "openapi",
+				Usage: "export OpenAPI document of the router",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "app",
+						Usage: "application name",
+						Value: "",
+					},
+					&cli.StringFlag{
+						Name:  "output",
+						Usage: "output filename",
+						Value: "openapi.json",
+					},
+					&cli.StringFlag{
+						Name:  "config",
+						Usage: "config filename",
+						Value: "application.toml",
+					},
+					&cli.StringFlag{
+						Name:  "title",
+						Usage: "title of the api, default is the service name",
+						Value: "",
+					},
+					&cli.StringFlag{
+						Name:  "version",
+						Usage: "version of the api",
+						Value: "0.0.1",
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					p := new(openapiParam)
+					if err := bindAndValide(ctx, p); err != nil {
+						return err
+					}
+					return p.export()
+				},
+			},
+			{ // list routes
+				Name:  "routes",
+				Usage: "list routes of the router",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "app",
+						Usage: "application name",
+						Value: "",
+					},
+					&cli.StringFlag{
+						Name:  "config",
+						Usage: "config filename",
+						Value: "application.toml",
+					},
+					&cli.StringFlag{
+						Name:  "path",
+						Usage: "only routes whose path contains it",
+						Value: "",
+					},
+					&cli.StringFlag{
+						Name:  "method",
+						Usage: "only routes of the method",
+						Value: "",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "print routes in json",
+						Value: false,
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					p := new(routesParam)
+					if err := bindAndValide(ctx, p); err != nil {
+						return err
+					}
+					return p.print()
+				},
+			},
+			{ // digest static files
+				Name:  "digest",
+				Usage: "digest and compress static files for caching",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "dir",
+						Usage: "static directory",
+						Value: "assets",
+					},
+					&cli.BoolFlag{
+						Name:  "clean",
+						Usage: "remove digested files",
+						Value: false,
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					p := new(digestParam)
+					if err := bindAndValide(ctx, p); err != nil {
+						return err
+					}
+					return p.digest()
+				},
+			},
 			{ // build service
 				Name:  "build",
 				Usage: "build service",
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/DOVECYJ/phoenix/cmd"
+	"github.com/urfave/cli/v2"
+)
+
+// A program to export OpenAPI document, it's run in the project since the
+// router is only known by the project.
+const openapiProgram = `package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"{{.Mod}}/lib/{{.App}}_web"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/openapi"
+)
+
+func main() {
+	phoenix.MustLoadConfig({{printf "%q" .Config}})
+	doc := openapi.Build({{.App}}web.NewRouter(), openapi.Info{
+		Title:   {{printf "%q" .Title}},
+		Version: {{printf "%q" .Version}},
+	})
+	data, err := json.MarshalIndent(doc, "", "  ")
+	phoenix.PanicError(err)
+	phoenix.PanicError(os.WriteFile({{printf "%q" .Output}}, data, 0644))
+}
+`
+
+// The params for export OpenAPI document.
+type openapiParam struct {
+	Mod     string `validate:"-"`
+	App     string `validate:"-"`
+	Output  string `validate:"required"` // output filename
+	Config  string `validate:"required"` // config filename
+	Title   string `validate:"-"`
+	Version string `validate:"-"`
+}
+
+func (p *openapiParam) bind(ctx *cli.Context, args ...string) {
+	p.App = ctx.String("app")
+	p.Output = ctx.String("output")
+	p.Config = ctx.String("config")
+	p.Title = ctx.String("title")
+	p.Version = ctx.String("version")
+}
+
+// Export document of the router built by NewRouter of the app endpoint.
+func (p *openapiParam) export() (err error) {
+	if p.Mod, err = getMod(); err != nil {
+		return err
+	}
+	if p.App, err = projectApp(p.App); err != nil {
+		return err
+	}
+	if err = runProgram("openapi", openapiProgram, p); err == nil {
+		fmt.Println("* export:", p.Output)
+	}
+	return err
+}
+
+// The app to use, it must be specified when there are many apps.
+func projectApp(app string) (string, error) {
+	if app != "" {
+		return app, nil
+	}
+	apps := getApps()
+	if len(apps) != 1 {
+		return "", errors.New("can not decide application, please specify an app with --app")
+	}
+	return apps[0], nil
+}
+
+// Write program src with data into _build and run it in the project, the
+// program is removed after running. Output of the program is only shown when
+// it fails.
+func runProgram(name, src string, data any) error {
+	temp, err := template.New(name).Parse(src)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join("_build", "phx_"+name)
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	f, err := os.Create(filepath.Join(dir, "main.go"))
+	if err != nil {
+		return err
+	}
+	err = temp.Execute(f, data)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	out, err := cmd.Cmd("go run ./" + filepath.ToSlash(dir)).Call()
+	if err != nil {
+		fmt.Fprint(os.Stderr, out)
+	}
+	return err
+}
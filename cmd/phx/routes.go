@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+)
+
+// A program to dump routes of the project into Output as json.
+const routesProgram = `package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"{{.Mod}}/lib/{{.App}}_web"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/router"
+)
+
+func main() {
+	phoenix.MustLoadConfig({{printf "%q" .Config}})
+	data, err := json.Marshal(router.Routes({{.App}}web.NewRouter()))
+	phoenix.PanicError(err)
+	phoenix.PanicError(os.WriteFile({{printf "%q" .Output}}, data, 0644))
+}
+`
+
+// A route read from the project, it's the json of router.RouteInfo.
+type route struct {
+	Method      string   `json:"method"`
+	Pattern     string   `json:"pattern"`
+	Name        string   `json:"name,omitempty"`
+	Handler     string   `json:"handler"`
+	Pipelines   []string `json:"pipelines"`
+	Middlewares []string `json:"middlewares"`
+}
+
+// The params for list routes.
+type routesParam struct {
+	Mod    string `validate:"-"`
+	App    string `validate:"-"`
+	Config string `validate:"required"` // config filename
+	Output string `validate:"-"`        // temp file of routes
+	Path   string `validate:"-"`        // only routes contain it
+	Method string `validate:"-"`        // only routes of it
+	JSON   bool   `validate:"-"`
+}
+
+func (p *routesParam) bind(ctx *cli.Context, args ...string) {
+	p.App = ctx.String("app")
+	p.Config = ctx.String("config")
+	p.Path = ctx.String("path")
+	p.Method = ctx.String("method")
+	p.JSON = ctx.Bool("json")
+}
+
+// Print routes of the router built by NewRouter of the app endpoint, the
+// server is not started.
+func (p *routesParam) print() (err error) {
+	if p.Mod, err = getMod(); err != nil {
+		return err
+	}
+	if p.App, err = projectApp(p.App); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "phx-routes-*.json")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	p.Output = f.Name()
+	if err = runProgram("routes", routesProgram, p); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(p.Output)
+	if err != nil {
+		return err
+	}
+	var routes []route
+	if err = json.Unmarshal(data, &routes); err != nil {
+		return err
+	}
+	return p.write(os.Stdout, p.filter(routes))
+}
+
+// Write routes to out as json or a table.
+func (p *routesParam) write(out io.Writer, routes []route) error {
+	if p.JSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(routes)
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tNAME\tHANDLER\tPIPELINES\tMIDDLEWARES")
+	for _, r := range routes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Method, r.Pattern, r.Name, r.Handler,
+			strings.Join(r.Pipelines, ", "), strings.Join(r.Middlewares, ", "))
+	}
+	return w.Flush()
+}
+
+func (p *routesParam) filter(routes []route) []route {
+	var matched []route
+	for _, r := range routes {
+		if p.Method != "" && !strings.EqualFold(r.Method, p.Method) {
+			continue
+		}
+		if p.Path != "" && !strings.Contains(r.Pattern, p.Path) {
+			continue
+		}
+		matched = append(matched, r)
+	}
+	if matched == nil {
+		matched = []route{}
+	}
+	return matched
+}
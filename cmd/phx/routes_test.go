@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testRoutes = []route{
+	{Method: "GET", Pattern: "/", Name: "page_index_path", Handler: "controllers.PageController.Index", Pipelines: []string{"browser"}, Middlewares: []string{"middleware.CSRF"}},
+	{Method: "GET", Pattern: "/users", Name: "users_path", Handler: "controllers.UserController.Index", Pipelines: []string{"browser"}, Middlewares: []string{"middleware.CSRF"}},
+	{Method: "POST", Pattern: "/users", Name: "users_path", Handler: "controllers.UserController.Create", Pipelines: []string{"browser"}, Middlewares: []string{"middleware.CSRF"}},
+	{Method: "GET", Pattern: "/api/users/{id}", Name: "api_user_path", Handler: "api.UserController.Show", Pipelines: []string{}, Middlewares: []string{}},
+}
+
+func TestRoutesFilter(t *testing.T) {
+	cases := []struct {
+		path, method string
+		want         []string
+	}{
+		{"", "", []string{"GET /", "GET /users", "POST /users", "GET /api/users/{id}"}},
+		{"/users", "", []string{"GET /users", "POST /users", "GET /api/users/{id}"}},
+		{"", "post", []string{"POST /users"}},
+		{"/api", "GET", []string{"GET /api/users/{id}"}},
+		{"/posts", "", []string{}},
+	}
+	for _, c := range cases {
+		p := routesParam{Path: c.path, Method: c.method}
+		got := []string{}
+		for _, r := range p.filter(testRoutes) {
+			got = append(got, r.Method+" "+r.Pattern)
+		}
+		if !slices.Equal(got, c.want) {
+			t.Errorf("path %q method %q: %q, want %q", c.path, c.method, got, c.want)
+		}
+	}
+}
+
+func TestRoutesWrite(t *testing.T) {
+	var buf bytes.Buffer
+	p := routesParam{JSON: true, Path: "/none"}
+	if err := p.write(&buf, p.filter(testRoutes)); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Errorf("no routes = %s, want []", got)
+	}
+
+	buf.Reset()
+	if err := p.write(&buf, testRoutes[3:]); err != nil {
+		t.Fatal(err)
+	}
+	var routes []route
+	if err := json.Unmarshal(buf.Bytes(), &routes); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(routes, testRoutes[3:]) {
+		t.Errorf("routes = %+v", routes)
+	}
+	// empty lists are kept, not null
+	if !strings.Contains(buf.String(), `"pipelines": []`) {
+		t.Errorf("json = %s", buf.String())
+	}
+
+	buf.Reset()
+	p.JSON = false
+	if err := p.write(&buf, testRoutes[:1]); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "METHOD  PATH  NAME") ||
+		strings.Join(strings.Fields(lines[1]), " ") != "GET / page_index_path controllers.PageController.Index browser middleware.CSRF" {
+		t.Errorf("table = %q", lines)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/DOVECYJ/phoenix/cmd"
@@ -19,14 +20,30 @@ import (
 	"{{.Mod}}/lib/{{.App}}/{{.Name}}/model"
 	"{{.Mod}}/pkg/repo"
 
+	"github.com/DOVECYJ/phoenix/paginate"
+	"github.com/DOVECYJ/phoenix/query"
 	"github.com/go-rel/changeset"
 	"github.com/go-rel/changeset/params"
 	"github.com/go-rel/rel/where"
 )
 
-func List{{plural .Entity}}(ctx context.Context) (data []model.{{.Entity}}, err error) {
-	err = repo.Repo.FindAll(ctx, &data)
-	return
+// Filterable, sortable and selectable columns of List{{plural .Entity}}.
+var {{.Entity}}Schema = query.Schema{
+	Filters: map[string]query.Kind{
+		"id": query.Int,
+		{{- range .Fields}}
+		"{{.Column}}": query.{{kind .Type}},
+		{{- end}}
+		"created_at": query.Time,
+		"updated_at": query.Time,
+	},
+	Sorts:  []string{"id",{{range .Fields}} "{{.Column}}",{{end}} "created_at", "updated_at"},
+	Fields: []string{"id",{{range .Fields}} "{{.Column}}",{{end}} "created_at", "updated_at"},
+	Sort:   "id", // offset pages are stable across requests
+}
+
+func List{{plural .Entity}}(ctx context.Context, q query.Query) (paginate.Page[model.{{.Entity}}], error) {
+	return query.Find[model.{{.Entity}}](ctx, repo.Repo, q)
 }
 
 func Get{{.Entity}}(ctx context.Context, id int) (data model.{{.Entity}}, err error) {
@@ -67,6 +84,7 @@ type contextParam struct {
 	Mod      string `validate:"-"`        // go module name
 	App      string `validate:"-"`        // application name
 	Entity   string `validate:"required"` // entity name
+	Fields   []field
 	filename string
 	_created bool
 }
@@ -81,6 +99,11 @@ func (p *contextParam) bind(ctx *cli.Context, args ...string) {
 	p.Name = args[0]
 	p.Entity = args[1]
 	p.App = ctx.String("app")
+	for _, field := range ctx.StringSlice("fields") {
+		if f, err := newField(strings.Split(field, ":")...); err == nil {
+			p.Fields = append(p.Fields, f)
+		}
+	}
 }
 
 func (p *contextParam) setMod(mod string) {
@@ -104,7 +127,7 @@ func (p *contextParam) executeTemplate() error {
 	}
 	// execute template
 	temp, err := template.New("context").
-		Funcs(template.FuncMap{"plural": inflection.Plural}).
+		Funcs(template.FuncMap{"plural": inflection.Plural, "kind": queryKind}).
 		Parse(contextTemplate)
 	if err != nil {
 		return err
@@ -124,3 +147,18 @@ func (p *contextParam) rollback() {
 		}
 	}
 }
+
+// Kind of query values for go type t.
+func queryKind(t string) string {
+	switch t {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return "Int"
+	case "float32", "float64":
+		return "Float"
+	case "bool":
+		return "Bool"
+	case "time.Time":
+		return "Time"
+	}
+	return "String"
+}
@@ -17,14 +17,17 @@ const (
 	controllerTemplate = `package controllers
 {{$entity := lower .Entity}}
 import (
-	"fmt"
 	"{{.Mod}}/lib/{{.App}}/{{.Name}}"
 	"{{.Mod}}/lib/{{.App}}/{{.Name}}/model"
 	{{$entity}}html "{{.Mod}}/lib/{{.App}}_web/controllers/{{snake .Entity}}_html"
 	"log/slog"
 	"net/http"
 
+	"github.com/DOVECYJ/phoenix"
 	"github.com/DOVECYJ/phoenix/binding"
+	"github.com/DOVECYJ/phoenix/flash"
+	"github.com/DOVECYJ/phoenix/paginate"
+	"github.com/DOVECYJ/phoenix/query"
 	"github.com/DOVECYJ/phoenix/render"
 	"github.com/DOVECYJ/phoenix/router"
 )
@@ -33,85 +36,103 @@ type {{.Entity}}Controller struct {
 	router.IResource
 }
 
+// Routes used by the controller and its pages, they are checked by
+// router.Load at startup.
+func init() {
+	router.Require("{{.Path}}_path", "{{snake .Entity}}_path")
+}
+
 func ({{.Entity}}Controller) Index(w http.ResponseWriter, r *http.Request) {
-	data, err := {{.Name}}.List{{plural .Entity}}(r.Context())
-	render.HTML(w, {{$entity}}html.Index(data, err))
+	q, err := query.FromRequest(r, {{.Name}}.{{.Entity}}Schema)
+	if err != nil {
+		render.HTML(w, r, {{$entity}}html.Index(paginate.Page[model.{{.Entity}}]{}, r.URL.Query(), err))
+		return
+	}
+	data, err := {{.Name}}.List{{plural .Entity}}(r.Context(), q)
+	render.HTML(w, r, {{$entity}}html.Index(data, r.URL.Query(), err))
 }
 
 func ({{.Entity}}Controller) Edit(w http.ResponseWriter, r *http.Request) {
-	id := r.Context().Value("id").(int)
+	id := phoenix.IntID.MustGet(r.Context())
 	data, err := {{.Name}}.Get{{.Entity}}(r.Context(), id)
 	if err != nil {
-		render.HTML(w, {{$entity}}html.Edit(data, err))
+		render.HTML(w, r, {{$entity}}html.Edit(data, err))
 		return
 	}
-	render.HTML(w, {{$entity}}html.Edit(data, nil))
+	render.HTML(w, r, {{$entity}}html.Edit(data, nil))
 }
 
 func ({{.Entity}}Controller) New(w http.ResponseWriter, r *http.Request) {
-	render.HTML(w, {{$entity}}html.New(nil))
+	render.HTML(w, r, {{$entity}}html.New(nil))
 }
 
 func ({{.Entity}}Controller) Show(w http.ResponseWriter, r *http.Request) {
-	id := r.Context().Value("id").(int)
+	id := phoenix.IntID.MustGet(r.Context())
 	data, err := {{.Name}}.Get{{.Entity}}(r.Context(), id)
 	if err != nil {
-		render.HTML(w, {{$entity}}html.Show(data, err))
+		render.HTML(w, r, {{$entity}}html.Show(data, err))
 		return
 	}
-	render.HTML(w, {{$entity}}html.Show(data, nil))
+	render.HTML(w, r, {{$entity}}html.Show(data, nil))
 }
 
 func ({{.Entity}}Controller) Create(w http.ResponseWriter, r *http.Request) {
 	params, err := binding.Attr(r)
 	if err != nil {
-		render.HTML(w, {{$entity}}html.New(err))
+		render.HTML(w, r, {{$entity}}html.New(err))
 		return
 	}
 	
 	data, _, err := {{.Name}}.Create{{.Entity}}(r.Context(), params)
 	if err != nil {
-		render.HTML(w, {{$entity}}html.New(err))
+		render.HTML(w, r, {{$entity}}html.New(err))
 		return
 	}
-	http.Redirect(w, r, fmt.Sprintf("/{{.Path}}/%d", data.ID), http.StatusFound)
+	flash.Put(w, r, flash.Info, "{{.Entity}} created successfully.")
+	http.Redirect(w, r, router.URL("{{snake .Entity}}_path", data.ID), http.StatusFound)
 }
 
 func ({{.Entity}}Controller) Update(w http.ResponseWriter, r *http.Request) {
-	id := r.Context().Value("id").(int)
+	id := phoenix.IntID.MustGet(r.Context())
 	params, err := binding.Attr(r)
 	if err != nil {
-		render.HTML(w, {{$entity}}html.Edit(model.{{.Entity}}{}, err))
+		render.HTML(w, r, {{$entity}}html.Edit(model.{{.Entity}}{}, err))
 		return
 	}
 	
 	data, err := {{.Name}}.Get{{.Entity}}(r.Context(), id)
 	if err != nil {
-		render.HTML(w, {{$entity}}html.Edit(data, err))
+		render.HTML(w, r, {{$entity}}html.Edit(data, err))
 		return
 	}
 	
 	_, err = {{.Name}}.Update{{.Entity}}(r.Context(), &data, params)
 	if err != nil {
-		render.HTML(w, {{$entity}}html.Edit(data, err))
+		render.HTML(w, r, {{$entity}}html.Edit(data, err))
 		return
 	}
-	http.Redirect(w, r, "/{{.Path}}", http.StatusFound)
+	flash.Put(w, r, flash.Info, "{{.Entity}} updated successfully.")
+	http.Redirect(w, r, router.URL("{{.Path}}_path"), http.StatusFound)
 }
 
 func ({{.Entity}}Controller) Delete(w http.ResponseWriter, r *http.Request) {
-	id := r.Context().Value("id").(int)
+	id := phoenix.IntID.MustGet(r.Context())
 	data, err := {{.Name}}.Get{{.Entity}}(r.Context(), id)
 	if err != nil {
-		http.Redirect(w, r, "/{{.Path}}", http.StatusFound)
+		slog.Error("get {{snake .Entity}}", "id", id, "error", err)
+		flash.Put(w, r, flash.Error, "Could not delete {{.Entity}}.")
+		http.Redirect(w, r, router.URL("{{.Path}}_path"), http.StatusFound)
 		return
 	}
 	err = {{.Name}}.Delete{{.Entity}}(r.Context(), data)
 	if err != nil {
-		slog.Error("delete user", "error", err)
+		slog.Error("delete {{snake .Entity}}", "id", id, "error", err)
+		flash.Put(w, r, flash.Error, "Could not delete {{.Entity}}.")
+		http.Redirect(w, r, router.URL("{{.Path}}_path"), http.StatusFound)
 		return
 	}
-	http.Redirect(w, r, "/{{.Path}}", http.StatusFound)
+	flash.Put(w, r, flash.Info, "{{.Entity}} deleted successfully.")
+	http.Redirect(w, r, router.URL("{{.Path}}_path"), http.StatusFound)
 }
 `
 
@@ -121,21 +142,29 @@ import (
 	"net/http"
 	"{{.Mod}}/lib/{{.App}}/{{.Name}}"
 
+	"github.com/DOVECYJ/phoenix"
 	"github.com/DOVECYJ/phoenix/binding"
+	"github.com/DOVECYJ/phoenix/paginate"
+	"github.com/DOVECYJ/phoenix/query"
 	"github.com/DOVECYJ/phoenix/render"
 )
 {{$Entities := plural .Entity}}
 func List{{$Entities}}(w http.ResponseWriter, r *http.Request) {
-	data, err := {{.Name}}.List{{$Entities}}(r.Context())
+	q, err := query.FromRequest(r, {{.Name}}.{{.Entity}}Schema)
 	if err != nil {
 		render.Render(w, err)
 		return
 	}
-	render.ApiData(w, data)
+	data, err := {{.Name}}.List{{$Entities}}(r.Context(), q)
+	if err != nil {
+		render.Render(w, err)
+		return
+	}
+	paginate.Render(w, r, data)
 }
 
 func Get{{.Entity}}(w http.ResponseWriter, r *http.Request) {
-	id := binding.ContextID(r)
+	id := phoenix.IntID.MustGet(r.Context())
 	data, err := {{.Name}}.Get{{.Entity}}(r.Context(), id)
 	if err != nil {
 		render.Render(w, err)
@@ -147,7 +176,7 @@ func Get{{.Entity}}(w http.ResponseWriter, r *http.Request) {
 func Create{{.Entity}}(w http.ResponseWriter, r *http.Request) {
 	param, err := binding.Attr(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		render.Render(w, err)
 		return
 	}
 	data, _, err := {{.Name}}.Create{{.Entity}}(r.Context(), param)
@@ -155,14 +184,14 @@ func Create{{.Entity}}(w http.ResponseWriter, r *http.Request) {
 		render.Render(w, err)
 		return
 	}
-	render.ApiData(w, data)
+	render.ApiData(w, data, render.Status(http.StatusCreated))
 }
 
 func Update{{.Entity}}(w http.ResponseWriter, r *http.Request) {
-	id := binding.ContextID(r)
+	id := phoenix.IntID.MustGet(r.Context())
 	param, err := binding.Attr(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		render.Render(w, err)
 		return
 	}
 	data, err := {{.Name}}.Get{{.Entity}}(r.Context(), id)
@@ -179,7 +208,7 @@ func Update{{.Entity}}(w http.ResponseWriter, r *http.Request) {
 }
 
 func Delete{{.Entity}}(w http.ResponseWriter, r *http.Request) {
-	id := binding.ContextID(r)
+	id := phoenix.IntID.MustGet(r.Context())
 	data, err := {{.Name}}.Get{{.Entity}}(r.Context(), id)
 	if err != nil {
 		render.Render(w, err)
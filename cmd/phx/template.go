@@ -1,7 +1,9 @@
 package main
 
 import (
+	"crypto/rand"
 	"embed"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
@@ -27,6 +29,7 @@ type config struct {
 	NoDatabase bool   // 不使用数据库
 	NoRedis    bool   // 不使用redis
 	NoHtml     bool   // 不生成HTML
+	SecretKey  string // 签名密钥
 }
 
 func (c config) getByName(key string) string {
@@ -64,6 +67,16 @@ func (c *config) bind(ctx *cli.Context, args ...string) {
 	c.NoDatabase = ctx.Bool("no-database")
 	c.NoRedis = ctx.Bool("no-redis")
 	c.NoHtml = ctx.Bool("no-html")
+	c.SecretKey = randomKey(48)
+}
+
+// Generate a random key of n bytes, encoded in base64.
+func randomKey(n int) string {
+	bs := make([]byte, n)
+	if _, err := rand.Read(bs); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bs)
 }
 
 // Initialize project in an empty direatory.
@@ -119,6 +132,9 @@ func initProject(c config) error {
 	if err = os.MkdirAll(filepath.Join(c.Dir, "_build"), os.ModePerm); err != nil {
 		return err
 	}
+	if err = os.MkdirAll(filepath.Join(c.Dir, "priv", "locales"), os.ModePerm); err != nil {
+		return err
+	}
 	if !c.NoDatabase {
 		if err = os.MkdirAll(filepath.Join(c.Dir, "priv", "repo", "migrations"), os.ModePerm); err != nil {
 			return err
@@ -8,6 +8,7 @@ import (
 	"text/template"
 
 	"github.com/azer/snakecase"
+	"github.com/jinzhu/inflection"
 	"github.com/urfave/cli/v2"
 )
 
@@ -17,10 +18,15 @@ package {{$entity}}html
 
 import "{{.Mod}}/lib/{{.App}}/{{.Name}}/model"
 import . "{{.Mod}}/lib/{{.App}}_web/components"
+import "github.com/DOVECYJ/phoenix/router"
 
 templ Edit(data model.{{.Entity}}, err error) {
 	@Layout() {
-
+		<form method="post" action={ templ.URL(router.URL("{{snake .Entity}}_path", data.ID)) }>
+			@CSRFInput()
+			<input type="hidden" name="_method" value="PUT"/>
+			<button type="submit" class="btn btn-primary">Save</button>
+		</form>
 	}
 }
 `
@@ -30,6 +36,7 @@ type editHtmlParam struct {
 	Mod      string `validate:"-"`        // go module name
 	App      string `validate:"-"`        // application name
 	Entity   string `validate:"required"` // entity name
+	Path     string // url path of resource
 	filename string
 	_created bool
 }
@@ -41,6 +48,7 @@ func (p *editHtmlParam) bind(ctx *cli.Context, args ...string) {
 	p.Name = args[0]
 	p.Entity = args[1]
 	p.App = ctx.String("app")
+	p.Path = snakecase.SnakeCase(inflection.Plural(p.Entity))
 }
 
 func (p *editHtmlParam) setMod(mod string) {
@@ -65,6 +73,7 @@ func (p *editHtmlParam) executeTemplate() error {
 	temp, err := template.New("edit.html").
 		Funcs(template.FuncMap{
 			"lower": strings.ToLower,
+			"snake": snakecase.SnakeCase,
 		}).
 		Parse(editHtmlTemplate)
 	if err != nil {
@@ -15,12 +15,15 @@ const indexHtmlTemplate = `
 {{- $entity := lower .Entity -}}
 package {{$entity}}html
 
+import "net/url"
 import "{{.Mod}}/lib/{{.App}}/{{.Name}}/model"
 import . "{{.Mod}}/lib/{{.App}}_web/components"
+import "github.com/DOVECYJ/phoenix/paginate"
 
-templ Index(data []model.{{.Entity}}, err error) {
+templ Index(data paginate.Page[model.{{.Entity}}], query url.Values, err error) {
 	@Layout() {
 
+		@Pagination(data.Meta, query)
 	}
 }`
 
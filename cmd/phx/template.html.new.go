@@ -7,6 +7,8 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/azer/snakecase"
+	"github.com/jinzhu/inflection"
 	"github.com/serenize/snaker"
 	"github.com/urfave/cli/v2"
 )
@@ -16,10 +18,14 @@ const newHtmlTemplate = `
 package {{$entity}}html
 
 import . "{{.Mod}}/lib/{{.App}}_web/components"
+import "github.com/DOVECYJ/phoenix/router"
 
 templ New(err error) {
 	@Layout() {
-
+		<form method="post" action={ templ.URL(router.URL("{{.Path}}_path")) }>
+			@CSRFInput()
+			<button type="submit" class="btn btn-primary">Save</button>
+		</form>
 	}
 }
 `
@@ -29,6 +35,7 @@ type newHtmlParam struct {
 	Mod      string `validate:"-"`        // go module name
 	App      string `validate:"-"`        // application name
 	Entity   string `validate:"required"` // entity name
+	Path     string // url path of resource
 	filename string
 	_created bool
 }
@@ -40,6 +47,7 @@ func (p *newHtmlParam) bind(ctx *cli.Context, args ...string) {
 	p.Name = args[0]
 	p.Entity = args[1]
 	p.App = ctx.String("app")
+	p.Path = snakecase.SnakeCase(inflection.Plural(p.Entity))
 }
 
 func (p *newHtmlParam) setMod(mod string) {
@@ -0,0 +1,111 @@
+package phoenix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-rel/rel"
+)
+
+func init() {
+	RegisterErrorType[rel.NotFoundError](http.StatusNotFound, 0, "resource not found")
+	RegisterErrorType[rel.ConstraintError](http.StatusConflict, 0, "resource conflict")
+	RegisterErrorType[FieldError](http.StatusUnprocessableEntity, 0, "validation failed")
+	RegisterErrorType[validator.ValidationErrors](http.StatusUnprocessableEntity, 0, "validation failed")
+	RegisterError(sql.ErrNoRows, http.StatusNotFound, 0, "resource not found")
+	RegisterError(os.ErrNotExist, http.StatusNotFound, 0, "resource not found")
+	RegisterError(os.ErrExist, http.StatusConflict, 0, "resource already exists")
+	RegisterError(os.ErrPermission, http.StatusForbidden, 0, "permission denied")
+	RegisterError(context.DeadlineExceeded, http.StatusGatewayTimeout, 0, "request timeout")
+}
+
+// ErrorInfo describes how an error is exposed to http clients.
+type ErrorInfo struct {
+	Status int    // http status code
+	Code   int    // business error code
+	Msg    string // public message, it is safe to show to clients
+}
+
+var (
+	errorMu       sync.RWMutex
+	errorMappings []func(error) (ErrorInfo, bool)
+)
+
+// Map sentinel error target to http status, error code and public message.
+// The error is matched by errors.Is. When msg is empty, the message of the
+// error itself will be shown to clients.
+//
+// Usage:
+//
+//	RegisterError(ErrUserBanned, http.StatusForbidden, 10001, "user is banned")
+func RegisterError(target error, status, code int, msg string) {
+	RegisterErrorFunc(func(err error) (ErrorInfo, bool) {
+		if !errors.Is(err, target) {
+			return ErrorInfo{}, false
+		}
+		return newErrorInfo(err, status, code, msg), true
+	})
+}
+
+// Map error type T to http status, error code and public message.
+// The error is matched by errors.As. When msg is empty, the message of the
+// error itself will be shown to clients.
+//
+// Usage:
+//
+//	RegisterErrorType[*QuotaError](http.StatusTooManyRequests, 10002, "")
+func RegisterErrorType[T error](status, code int, msg string) {
+	RegisterErrorFunc(func(err error) (ErrorInfo, bool) {
+		var target T
+		if !errors.As(err, &target) {
+			return ErrorInfo{}, false
+		}
+		return newErrorInfo(target, status, code, msg), true
+	})
+}
+
+// Register a custom error matcher. Matchers registered later take precedence,
+// so application can override the default mappings.
+func RegisterErrorFunc(match func(error) (ErrorInfo, bool)) {
+	errorMu.Lock()
+	defer errorMu.Unlock()
+	errorMappings = append(errorMappings, match)
+}
+
+// Look up the ErrorInfo of err. CodeError is mapped to 400 with its own code
+// and message when it is not registered. Any other unknown error is mapped to
+// 500 and its message is not public.
+func LookupError(err error) ErrorInfo {
+	errorMu.RLock()
+	defer errorMu.RUnlock()
+	for i := len(errorMappings) - 1; i >= 0; i-- {
+		if info, ok := errorMappings[i](err); ok {
+			return info
+		}
+	}
+	var cerr CodeError
+	if errors.As(err, &cerr) {
+		return ErrorInfo{
+			Status: http.StatusBadRequest,
+			Code:   cerr.Code(),
+			Msg:    cerr.Error(),
+		}
+	}
+	return ErrorInfo{
+		Status: http.StatusInternalServerError,
+		Msg:    http.StatusText(http.StatusInternalServerError),
+	}
+}
+
+func newErrorInfo(err error, status, code int, msg string) ErrorInfo {
+	info := ErrorInfo{Status: status, Code: code, Msg: msg}
+	if msg == "" {
+		info.Msg = err.Error()
+	}
+	return info
+}
@@ -0,0 +1,43 @@
+package phoenix
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+type quotaError struct{ limit int }
+
+func (e *quotaError) Error() string { return fmt.Sprintf("quota %d exceeded", e.limit) }
+
+func TestLookupError(t *testing.T) {
+	errBanned := errors.New("user is banned")
+	errLocked := errors.New("account locked")
+	RegisterError(errBanned, http.StatusForbidden, 10001, "")
+	RegisterError(errBanned, http.StatusConflict, 10002, "banned") // later wins
+	RegisterErrorType[*quotaError](http.StatusTooManyRequests, 10003, "")
+	RegisterErrorFunc(func(err error) (ErrorInfo, bool) {
+		return ErrorInfo{Status: http.StatusLocked, Msg: "locked"}, errors.Is(err, errLocked)
+	})
+
+	cases := []struct {
+		name string
+		err  error
+		want ErrorInfo
+	}{
+		{"later registration wins", errBanned, ErrorInfo{http.StatusConflict, 10002, "banned"}},
+		{"wrapped sentinel", fmt.Errorf("find user: %w", sql.ErrNoRows), ErrorInfo{http.StatusNotFound, 0, "resource not found"}},
+		{"wrapped type, own message", fmt.Errorf("upload: %w", &quotaError{3}), ErrorInfo{http.StatusTooManyRequests, 10003, "quota 3 exceeded"}},
+		{"func", fmt.Errorf("login: %w", errLocked), ErrorInfo{http.StatusLocked, 0, "locked"}},
+		{"code error", fmt.Errorf("pay: %w", ErrCode(20001).WithMsg("balance is not enough")), ErrorInfo{http.StatusBadRequest, 20001, "balance is not enough"}},
+		{"field error", FieldError{"name": errors.New("required")}, ErrorInfo{http.StatusUnprocessableEntity, 0, "validation failed"}},
+		{"unknown", errors.New("connection refused"), ErrorInfo{http.StatusInternalServerError, 0, "Internal Server Error"}},
+	}
+	for _, c := range cases {
+		if got := LookupError(c.err); got != c.want {
+			t.Errorf("%s: got %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Package flash provide Phoenix style flash messages, which are stored in a
+// HMAC signed cookie and read once on the next request.
+//
+// Usage:
+//
+//	root.Use(middleware.Assigns, flash.Fetch)
+//
+//	func (UserController) Create(w http.ResponseWriter, r *http.Request) {
+//		...
+//		flash.Put(w, r, flash.Info, "User created successfully.")
+//		http.Redirect(w, r, "/users", http.StatusFound)
+//	}
+//
+// Then the message can be read by phoenix.Flash(ctx) in templ components.
+package flash
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/spf13/viper"
+)
+
+const (
+	Info  = "info"
+	Error = "error"
+
+	cookieName = "_flash"
+	stateKey   = phoenix.CtxKey("phoenix.flash")
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid flash signature")
+
+	secret     []byte
+	secretOnce sync.Once
+)
+
+func init() {
+	phoenix.AfterLoadCondig("flash", ConfigFlash)
+}
+
+// Read the signing key from secret_key_base in config. A random key is used
+// when it is not configured, then flash will not survive a restart.
+//
+//	secret_key_base = 'a long random string'
+func ConfigFlash() error {
+	if key := viper.GetString("secret_key_base"); key != "" {
+		SetSecret([]byte(key))
+	}
+	return nil
+}
+
+// Set the key to sign flash cookie.
+func SetSecret(key []byte) {
+	secret = key
+}
+
+func signingKey() []byte {
+	secretOnce.Do(func() {
+		if secret != nil {
+			return
+		}
+		slog.Warn("secret_key_base is not configured, use a random key for flash")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(err)
+		}
+	})
+	return secret
+}
+
+// flash state of one request
+type state struct {
+	assigns  *phoenix.Assigns
+	incoming bool              // request carried a flash cookie
+	pending  map[string]string // flash put in this request
+	secure   bool
+}
+
+// Middleware that read flash from cookie into assigns and delete the cookie.
+// Flash put in the request is written to cookie only when it responses a
+// redirect, otherwise they are shown in current page.
+func Fetch(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, assigns := phoenix.EnsureAssigns(r.Context())
+		st := &state{assigns: assigns, secure: r.TLS != nil}
+		if c, err := r.Cookie(cookieName); err == nil {
+			st.incoming = true
+			if messages, err := decode(c.Value); err == nil {
+				assigns.Flash = messages
+			} else {
+				slog.Warn("fetch flash", "error", err)
+			}
+		}
+		ctx = context.WithValue(ctx, stateKey, st)
+		fw := &writer{ResponseWriter: w, state: st}
+		next.ServeHTTP(fw, r.WithContext(ctx))
+		// handler wrote nothing, the implicit 200 still needs the cookie
+		fw.persist(http.StatusOK)
+	})
+}
+
+// Put a flash message of kind, it can be read on the next request after
+// redirect. Kind is usually Info or Error.
+func Put(w http.ResponseWriter, r *http.Request, kind, msg string) {
+	st, ok := r.Context().Value(stateKey).(*state)
+	if !ok {
+		// without Fetch, write cookie directly
+		http.SetCookie(w, newCookie(map[string]string{kind: msg}, r.TLS != nil))
+		return
+	}
+	if st.pending == nil {
+		st.pending = map[string]string{}
+	}
+	st.pending[kind] = msg
+	// also shown in current page when it is not redirected
+	if st.assigns.Flash == nil {
+		st.assigns.Flash = map[string]string{}
+	}
+	st.assigns.Flash[kind] = msg
+}
+
+// Get the flash message of kind in current request.
+func Get(r *http.Request, kind string) string {
+	return phoenix.Flash(r.Context())[kind]
+}
+
+// writer persist flash before the header is written.
+type writer struct {
+	http.ResponseWriter
+	state   *state
+	written bool
+}
+
+func (w *writer) WriteHeader(code int) {
+	w.persist(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *writer) persist(code int) {
+	if w.written {
+		return
+	}
+	w.written = true
+	st := w.state
+	switch {
+	case st.pending != nil && code >= 300 && code < 400:
+		http.SetCookie(w.ResponseWriter, newCookie(st.pending, st.secure))
+	case st.incoming:
+		http.SetCookie(w.ResponseWriter, &http.Cookie{Name: cookieName, Path: "/", MaxAge: -1})
+	}
+}
+
+func (w *writer) Write(bs []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(bs)
+}
+
+// Unwrap is used by http.ResponseController.
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func newCookie(messages map[string]string, secure bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     cookieName,
+		Value:    encode(messages),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// Encode messages as base64(json).base64(hmac)
+func encode(messages map[string]string) string {
+	bs, _ := json.Marshal(messages)
+	payload := base64.RawURLEncoding.EncodeToString(bs)
+	return payload + "." + sign(payload)
+}
+
+func decode(value string) (map[string]string, error) {
+	payload, sig, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(sign(payload))) {
+		return nil, ErrInvalidSignature
+	}
+	bs, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, err
+	}
+	var messages map[string]string
+	err = json.Unmarshal(bs, &messages)
+	return messages, err
+}
+
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, signingKey())
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,53 @@
+package flash
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix"
+)
+
+func TestFlashAfterRedirect(t *testing.T) {
+	SetSecret([]byte("test secret"))
+	var shown map[string]string
+	h := Fetch(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/create" {
+			Put(w, r, Info, "created")
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		shown = phoenix.Flash(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/create", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != cookieName {
+		t.Fatalf("flash cookie not set: %v", cookies)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if shown[Info] != "created" {
+		t.Fatalf("got: %v", shown)
+	}
+	cookies = w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Fatalf("flash cookie not deleted: %v", cookies)
+	}
+}
+
+func TestFlashTampered(t *testing.T) {
+	SetSecret([]byte("test secret"))
+	value := encode(map[string]string{Error: "oops"})
+	if _, err := decode(value + "x"); err != ErrInvalidSignature {
+		t.Fatalf("got: %v", err)
+	}
+	messages, err := decode(value)
+	if err != nil || messages[Error] != "oops" {
+		t.Fatalf("got: %v, %v", messages, err)
+	}
+}
@@ -0,0 +1,251 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/go-playground/validator/v10"
+	"github.com/go-rel/changeset"
+)
+
+// Messages of changeset validations and the validation key they map to. The
+// message vars of changeset can be changed, so they are read when matching.
+var changesetMessages = []struct {
+	message *string
+	key     string
+}{
+	{&changeset.ValidateRequiredErrorMessage, "required"},
+	{&changeset.ValidateMinErrorMessage, "min.number"},
+	{&changeset.ValidateMaxErrorMessage, "max.number"},
+	{&changeset.ValidateRangeErrorMessage, "range"},
+	{&changeset.ValidateInclusionErrorMessage, "oneof"},
+	{&changeset.ValidateExclusionErrorMessage, "exclusion"},
+	{&changeset.ValidatePatternErrorMessage, "format"},
+	{&changeset.ValidateRegexpErrorMessage, "format"},
+	{&changeset.CastErrorMessage, "invalid"},
+}
+
+// compiled changeset messages
+var changesetPatterns sync.Map
+
+// Translate err into the locale of ctx by Default bundle, see Bundle.Error.
+func Error(ctx context.Context, err error) string {
+	return Default.Error(phoenix.Locale(ctx), err)
+}
+
+// Translate errors of each field into the locale of ctx by Default bundle,
+// see Bundle.Errors.
+func Errors(ctx context.Context, err error) map[string]string {
+	return Default.Errors(phoenix.Locale(ctx), err)
+}
+
+// Translate errors of each field into the locale of ctx by Default bundle,
+// see Bundle.ValidationErrors.
+func ValidationErrors(ctx context.Context, err error) map[string]phoenix.ValidationError {
+	return Default.ValidationErrors(phoenix.Locale(ctx), err)
+}
+
+// Translate err into locale. Errors of validator and changeset are translated
+// by messages under validation, with field and param as arguments. Field name
+// is translated by key fields.<name> when there is one. Other errors use its
+// message as key.
+//
+//	[validation]
+//	required = "%{field}不能为空"
+//	[validation.min]
+//	string = "%{field}至少%{param}个字符"
+//	number = "%{field}不能小于%{param}"
+func (b *Bundle) Error(locale string, err error) string {
+	if err == nil {
+		return ""
+	}
+	var (
+		ferr phoenix.FieldError
+		verr validator.ValidationErrors
+	)
+	if errors.As(err, &ferr) || errors.As(err, &verr) {
+		return join(b.Errors(locale, err))
+	}
+	return b.ValidationError(locale, err).Message
+}
+
+// Translate errors of each field into locale. err can be phoenix.FieldError
+// or validator.ValidationErrors, otherwise nil is returned.
+func (b *Bundle) Errors(locale string, err error) map[string]string {
+	verrs := b.ValidationErrors(locale, err)
+	if verrs == nil {
+		return nil
+	}
+	m := make(map[string]string, len(verrs))
+	for k, v := range verrs {
+		m[k] = v.Message
+	}
+	return m
+}
+
+// Same to Errors but each error is a phoenix.ValidationError with code and
+// params.
+func (b *Bundle) ValidationErrors(locale string, err error) map[string]phoenix.ValidationError {
+	var (
+		ferr phoenix.FieldError
+		verr validator.ValidationErrors
+	)
+	switch {
+	case errors.As(err, &ferr):
+		m := make(map[string]phoenix.ValidationError, len(ferr))
+		for k, v := range ferr {
+			m[k] = b.validationError(locale, k, v)
+		}
+		return m
+	case errors.As(err, &verr):
+		m := make(map[string]phoenix.ValidationError, len(verr))
+		for _, fe := range verr {
+			m[fe.Field()] = b.ValidationError(locale, fe)
+		}
+		return m
+	}
+	return nil
+}
+
+// Convert err of one field into a phoenix.ValidationError whose message is
+// translated into locale.
+func (b *Bundle) ValidationError(locale string, err error) phoenix.ValidationError {
+	return b.validationError(locale, "", err)
+}
+
+// A ValidationError with code is translated by validation.<code> when the
+// field is known, otherwise by its message.
+func (b *Bundle) validationError(locale, field string, err error) phoenix.ValidationError {
+	var (
+		fe validator.FieldError
+		ce changeset.Error
+		ve phoenix.ValidationError
+	)
+	switch {
+	case errors.As(err, &fe):
+		ve = phoenix.ValidationError{Code: fe.Tag(), Err: err}
+		if fe.Param() != "" {
+			ve.Params = map[string]any{"param": fe.Param()}
+		}
+		ve.Message = b.validation(locale, fe.Tag(), kindOf(fe.Kind()), map[string]any{
+			"field": b.label(locale, fe.Field()),
+			"param": fe.Param(),
+		})
+	case errors.As(err, &ce):
+		ve = b.changeset(locale, ce)
+		ve.Err = err
+	case errors.As(err, &ve):
+		if _, _, ok := b.lookup(b.parse(locale), "validation."+ve.Code); ok && field != "" {
+			args := map[string]any{"field": b.label(locale, field)}
+			for k, v := range ve.Params {
+				args[k] = v
+			}
+			ve.Message = b.validation(locale, ve.Code, "", args)
+		} else {
+			ve.Message = b.Translate(locale, ve.Message, ve.Params)
+		}
+	default:
+		ve = phoenix.AsValidationError(err)
+		ve.Message = b.Translate(locale, ve.Message)
+	}
+	return ve
+}
+
+// Translate a validation by keys from specific to general:
+// validation.<tag>.<kind>, validation.<tag> and validation.invalid.
+func (b *Bundle) validation(locale, tag, kind string, args map[string]any) string {
+	t := b.parse(locale)
+	keys := []string{"validation." + tag, "validation.invalid"}
+	if kind != "" {
+		keys = append([]string{"validation." + tag + "." + kind}, keys...)
+	}
+	for _, key := range keys {
+		if msg, t, ok := b.lookup(t, key); ok {
+			return msg.format(t, args)
+		}
+	}
+	return interpolate("%{field} is invalid", args)
+}
+
+func (b *Bundle) changeset(locale string, ce changeset.Error) phoenix.ValidationError {
+	for _, m := range changesetMessages {
+		pattern := changesetPattern(*m.message)
+		match := pattern.FindStringSubmatch(ce.Message)
+		if match == nil {
+			continue
+		}
+		params := map[string]any{}
+		for i, name := range pattern.SubexpNames() {
+			if name != "" && name != "field" {
+				params[name] = match[i]
+			}
+		}
+		if values, ok := params["values"]; ok {
+			params["param"] = values
+			delete(params, "values")
+		}
+		args := map[string]any{"field": b.label(locale, ce.Field)}
+		for k, v := range params {
+			args[k] = v
+		}
+		code, _, _ := strings.Cut(m.key, ".")
+		ve := phoenix.ValidationError{Code: code, Message: b.validation(locale, m.key, "", args)}
+		if len(params) > 0 {
+			ve.Params = params
+		}
+		return ve
+	}
+	return phoenix.ValidationError{Code: "invalid", Message: b.Translate(locale, ce.Message)}
+}
+
+// Compile message like "{field} must be more than {min}" into a regexp.
+func changesetPattern(message string) *regexp.Regexp {
+	if p, ok := changesetPatterns.Load(message); ok {
+		return p.(*regexp.Regexp)
+	}
+	placeholder := regexp.MustCompile(`\\\{(\w+)\\\}`)
+	expr := placeholder.ReplaceAllString(regexp.QuoteMeta(message), `(?P<$1>.+?)`)
+	p := regexp.MustCompile("^" + expr + "$")
+	changesetPatterns.Store(message, p)
+	return p
+}
+
+func (b *Bundle) label(locale, field string) string {
+	if msg, t, ok := b.lookup(b.parse(locale), "fields."+field); ok {
+		return msg.format(t, nil)
+	}
+	return field
+}
+
+func kindOf(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return ""
+}
+
+func join(messages map[string]string) string {
+	keys := make([]string, 0, len(messages))
+	for k := range messages {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = messages[k]
+	}
+	return strings.Join(lines, "; ")
+}
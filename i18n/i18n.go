@@ -0,0 +1,397 @@
+// Package i18n translate messages, validation errors and templ text by the
+// locale of request.
+//
+// Message catalogs are TOML or JSON files named by locale in priv/locales:
+//
+//	priv/locales/en.toml
+//	priv/locales/zh-CN.toml
+//
+// A catalog maps message key to translation, nested tables are flattened with
+// dot. %{name} in translation is replaced by the argument with same name, and
+// a table of plural forms is selected by the count argument:
+//
+//	"Listing users" = "用户列表"
+//	"%{count} users" = { one = "%{count} user", other = "%{count} users" }
+//
+//	[validation]
+//	required = "%{field}不能为空"
+//
+// When a key is not found in the locale or its parents, the fallback locale is
+// tried, then the key itself is returned. So a key can be plain English text,
+// and only other languages need a catalog.
+//
+// Usage in templ components:
+//
+//	<h1>{ i18n.T(ctx, "Listing users") }</h1>
+//	<span>{ i18n.T(ctx, "%{count} users", "count", len(users)) }</span>
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/viper"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*
+var builtin embed.FS
+
+// The bundle used by package level functions, it contains the builtin
+// validation messages.
+var Default = NewBundle("en")
+
+func init() {
+	if err := Default.LoadFS(builtin, "locales"); err != nil {
+		panic(err)
+	}
+	phoenix.BeforeLoadConfig("i18n", func() {
+		viper.SetDefault("i18n.default", "en")
+		viper.SetDefault("i18n.dir", "priv/locales")
+	})
+	phoenix.AfterLoadCondig("i18n", ConfigI18n)
+}
+
+// Set fallback locale and load catalogs of Default bundle by config. It's ok
+// that the dir does not exist.
+//
+//	[i18n]
+//	default = 'zh-CN'
+//	dir = 'priv/locales'
+func ConfigI18n() error {
+	if err := Default.SetFallback(viper.GetString("i18n.default")); err != nil {
+		return err
+	}
+	dir := viper.GetString("i18n.dir")
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	return Default.LoadDir(dir)
+}
+
+// Plural forms in catalog.
+var pluralForms = map[string]plural.Form{
+	"zero":  plural.Zero,
+	"one":   plural.One,
+	"two":   plural.Two,
+	"few":   plural.Few,
+	"many":  plural.Many,
+	"other": plural.Other,
+}
+
+// A translation, forms is nil when it has no plural forms.
+type message struct {
+	text  string
+	forms map[plural.Form]string
+}
+
+// Bundle is a set of message catalogs of different locales.
+type Bundle struct {
+	mu       sync.RWMutex
+	fallback language.Tag
+	catalogs map[language.Tag]map[string]message
+	matcher  language.Matcher // built lazily, reset when catalogs changed
+}
+
+// Create a bundle with the fallback locale.
+func NewBundle(fallback string) *Bundle {
+	return &Bundle{
+		fallback: language.Make(fallback),
+		catalogs: map[language.Tag]map[string]message{},
+	}
+}
+
+// Set the locale used when a message is not found in requested locale.
+func (b *Bundle) SetFallback(locale string) error {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.fallback, b.matcher = tag, nil
+	b.mu.Unlock()
+	return nil
+}
+
+// Load all *.toml and *.json catalogs in dir.
+func (b *Bundle) LoadDir(dir string) error {
+	return b.LoadFS(os.DirFS(dir), ".")
+}
+
+// Load all *.toml and *.json catalogs in dir of fsys, the file name without
+// extension is the locale.
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		ext := path.Ext(e.Name())
+		if e.IsDir() || (ext != ".toml" && ext != ".json") {
+			continue
+		}
+		bs, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		var messages map[string]any
+		if ext == ".toml" {
+			err = toml.Unmarshal(bs, &messages)
+		} else {
+			err = json.Unmarshal(bs, &messages)
+		}
+		if err != nil {
+			return fmt.Errorf("load %s: %w", e.Name(), err)
+		}
+		if err = b.AddMessages(strings.TrimSuffix(e.Name(), ext), messages); err != nil {
+			return fmt.Errorf("load %s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Add messages of locale, a message with same key will be replaced. Empty
+// translation is ignored, so an extracted but not translated key still falls
+// back.
+func (b *Bundle) AddMessages(locale string, messages map[string]any) error {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	catalog := b.catalogs[tag]
+	if catalog == nil {
+		catalog = map[string]message{}
+		b.catalogs[tag] = catalog
+		b.matcher = nil
+	}
+	return flatten(catalog, "", messages)
+}
+
+func flatten(catalog map[string]message, prefix string, messages map[string]any) error {
+	for k, v := range messages {
+		key := prefix + k
+		switch v := v.(type) {
+		case string:
+			if v != "" {
+				catalog[key] = message{text: v}
+			}
+		case map[string]any:
+			if msg, ok := pluralMessage(v); ok {
+				catalog[key] = msg
+			} else if err := flatten(catalog, key+".", v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("message %s: unsupported type %T", key, v)
+		}
+	}
+	return nil
+}
+
+// A table is plural forms when all keys are plural categories with other.
+func pluralMessage(m map[string]any) (message, bool) {
+	other, ok := m["other"].(string)
+	if !ok {
+		return message{}, false
+	}
+	msg := message{text: other, forms: map[plural.Form]string{}}
+	for k, v := range m {
+		form, ok := pluralForms[k]
+		if !ok {
+			return message{}, false
+		}
+		s, ok := v.(string)
+		if !ok {
+			return message{}, false
+		}
+		msg.forms[form] = s
+	}
+	return msg, msg.text != ""
+}
+
+// Supported locales, the fallback is the first.
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	tags := b.tags()
+	locales := make([]string, len(tags))
+	for i, tag := range tags {
+		locales[i] = tag.String()
+	}
+	return locales
+}
+
+func (b *Bundle) tags() []language.Tag {
+	tags := []language.Tag{b.fallback}
+	for tag := range b.catalogs {
+		if tag != b.fallback {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Match the best supported locale for prefs, prefs are locales or values of
+// Accept-Language in order of preference. It returns the fallback when none
+// is matched.
+func (b *Bundle) Match(prefs ...string) string {
+	var want []language.Tag
+	for _, pref := range prefs {
+		tags, _, err := language.ParseAcceptLanguage(pref)
+		if err == nil {
+			want = append(want, tags...)
+		}
+	}
+	b.mu.Lock()
+	if b.matcher == nil {
+		b.matcher = language.NewMatcher(b.tags())
+	}
+	matcher, fallback := b.matcher, b.fallback
+	b.mu.Unlock()
+	if len(want) == 0 {
+		return fallback.String()
+	}
+	tag, _, conf := matcher.Match(want...)
+	if conf == language.No {
+		return fallback.String()
+	}
+	// drop extensions like -u-rg added by matcher
+	base, script, region := tag.Raw()
+	tag, _ = language.Compose(base, script, region)
+	return tag.String()
+}
+
+// Translate key into locale with args. args are name and value pairs, or a
+// single map[string]any. The count argument selects plural forms.
+func (b *Bundle) Translate(locale, key string, args ...any) string {
+	msg, tag, ok := b.lookup(b.parse(locale), key)
+	if !ok {
+		return interpolate(key, toArgs(args))
+	}
+	return msg.format(tag, toArgs(args))
+}
+
+// Parse locale, it's the fallback when locale is invalid.
+func (b *Bundle) parse(locale string) language.Tag {
+	if tag, err := language.Parse(locale); err == nil {
+		return tag
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.fallback
+}
+
+// Find key in tag, its parents and the fallback.
+func (b *Bundle) lookup(tag language.Tag, key string) (message, language.Tag, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for t := tag; ; t = t.Parent() {
+		if msg, ok := b.catalogs[t][key]; ok {
+			return msg, t, true
+		}
+		if t == language.Und {
+			break
+		}
+	}
+	if msg, ok := b.catalogs[b.fallback][key]; ok {
+		return msg, b.fallback, true
+	}
+	return message{}, tag, false
+}
+
+func (m message) format(tag language.Tag, args map[string]any) string {
+	text := m.text
+	if m.forms != nil {
+		if n, ok := count(args["count"]); ok {
+			if s, ok := m.forms[plural.Cardinal.MatchPlural(tag, n, 0, 0, 0, 0)]; ok {
+				text = s
+			}
+		}
+	}
+	return interpolate(text, args)
+}
+
+func count(v any) (int, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(min(max(rv.Int(), -math.MaxInt32), math.MaxInt32)), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(min(rv.Uint(), math.MaxInt32)), true
+	}
+	return 0, false
+}
+
+func toArgs(args []any) map[string]any {
+	if len(args) == 1 {
+		if m, ok := args[0].(map[string]any); ok {
+			return m
+		}
+	}
+	m := make(map[string]any, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		m[fmt.Sprint(args[i])] = args[i+1]
+	}
+	return m
+}
+
+// Replace %{name} in s by args.
+func interpolate(s string, args map[string]any) string {
+	if len(args) == 0 || !strings.Contains(s, "%{") {
+		return s
+	}
+	var sb strings.Builder
+	for {
+		i := strings.Index(s, "%{")
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(s[i:], '}')
+		if j < 0 {
+			break
+		}
+		sb.WriteString(s[:i])
+		if v, ok := args[s[i+2:i+j]]; ok {
+			fmt.Fprint(&sb, v)
+		} else {
+			sb.WriteString(s[i : i+j+1])
+		}
+		s = s[i+j+1:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
+
+// Translate key into the locale of ctx by Default bundle, see Bundle.Translate.
+func T(ctx context.Context, key string, args ...any) string {
+	return Default.Translate(phoenix.Locale(ctx), key, args...)
+}
+
+// Translate key into locale by Default bundle.
+func Translate(locale, key string, args ...any) string {
+	return Default.Translate(locale, key, args...)
+}
+
+// Load catalogs in dir into Default bundle.
+func LoadDir(dir string) error {
+	return Default.LoadDir(dir)
+}
+
+// Add messages of locale into Default bundle.
+func AddMessages(locale string, messages map[string]any) error {
+	return Default.AddMessages(locale, messages)
+}
@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestBundle(t *testing.T) *Bundle {
+	t.Helper()
+	b := NewBundle("en")
+	err := b.LoadFS(fstest.MapFS{
+		"locales/en.toml": {Data: []byte(`
+"%{count} users" = { one = "%{count} user", other = "%{count} users" }
+[users]
+title = "Users"
+`)},
+		"locales/zh-CN.toml": {Data: []byte(`
+"Hello %{name}" = "你好 %{name}"
+"%{count} users" = { other = "%{count} 个用户" }
+`)},
+		"locales/ru.json": {Data: []byte(`{
+  "%{count} files": {"one": "%{count} файл", "few": "%{count} файла", "many": "%{count} файлов", "other": "%{count} файла"},
+  "untranslated": ""
+}`)},
+	}, "locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestTranslate(t *testing.T) {
+	b := newTestBundle(t)
+	cases := []struct {
+		locale, key string
+		args        []any
+		want        string
+	}{
+		{"en", "users.title", nil, "Users"},
+		{"en", "%{count} users", []any{"count", 1}, "1 user"},
+		{"en", "%{count} users", []any{"count", 2}, "2 users"},
+		{"en", "%{count} users", []any{"count", 0}, "0 users"},
+		{"zh-CN", "%{count} users", []any{"count", 1}, "1 个用户"},
+		{"ru", "%{count} files", []any{"count", 1}, "1 файл"},
+		{"ru", "%{count} files", []any{"count", 3}, "3 файла"},
+		{"ru", "%{count} files", []any{"count", 5}, "5 файлов"},
+		{"ru", "%{count} files", []any{"count", 21}, "21 файл"},
+		{"zh-CN", "Hello %{name}", []any{map[string]any{"name": "Bob"}}, "你好 Bob"},
+		{"zh-Hans-CN", "users.title", nil, "Users"},                                  // fallback
+		{"ru", "untranslated", nil, "untranslated"},                                  // empty translation falls back
+		{"en", "Hi %{name}, %{missing}", []any{"name", "Bob"}, "Hi Bob, %{missing}"}, // key itself
+		{"not a locale", "users.title", nil, "Users"},
+	}
+	for _, c := range cases {
+		if got := b.Translate(c.locale, c.key, c.args...); got != c.want {
+			t.Errorf("Translate(%s, %q, %v) = %q, want %q", c.locale, c.key, c.args, got, c.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	b := newTestBundle(t)
+	cases := []struct {
+		prefs []string
+		want  string
+	}{
+		{nil, "en"},
+		{[]string{"zh-CN"}, "zh-CN"},
+		{[]string{"zh-CN,zh;q=0.9,en;q=0.8"}, "zh-CN"},
+		{[]string{"fr-FR,ru;q=0.8,en;q=0.5"}, "ru"},
+		{[]string{"ja"}, "en"},
+		{[]string{"invalid;;", "ru"}, "ru"},
+	}
+	for _, c := range cases {
+		if got := b.Match(c.prefs...); got != c.want {
+			t.Errorf("Match(%q) = %s, want %s", c.prefs, got, c.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	Default = newTestBundle(t)
+	defer func() {
+		Default = NewBundle("en")
+		Default.LoadFS(builtin, "locales")
+	}()
+	cases := []struct {
+		target, cookie, accept, want string
+	}{
+		{"/", "", "ru", "ru"},
+		{"/", "zh-CN", "ru", "zh-CN"},
+		{"/?locale=ru", "zh-CN", "en", "ru"},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", c.target, nil)
+		if c.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: LocaleParam, Value: c.cookie})
+		}
+		r.Header.Set("Accept-Language", c.accept)
+		if got := Negotiate(r); got != c.want {
+			t.Errorf("Negotiate(%s, %s, %s) = %s, want %s", c.target, c.cookie, c.accept, got, c.want)
+		}
+	}
+}
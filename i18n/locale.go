@@ -0,0 +1,50 @@
+package i18n
+
+import (
+	"net/http"
+
+	"github.com/DOVECYJ/phoenix"
+)
+
+// Name of the query param and cookie to choose locale.
+const LocaleParam = "locale"
+
+// Negotiate the locale of r by Default bundle. The query param locale comes
+// first, then the locale cookie and Accept-Language header.
+func Negotiate(r *http.Request) string {
+	var prefs []string
+	if locale := r.URL.Query().Get(LocaleParam); locale != "" {
+		prefs = append(prefs, locale)
+	}
+	if c, err := r.Cookie(LocaleParam); err == nil && c.Value != "" {
+		prefs = append(prefs, c.Value)
+	}
+	if accept := r.Header.Get("Accept-Language"); accept != "" {
+		prefs = append(prefs, accept)
+	}
+	return Default.Match(prefs...)
+}
+
+// Middleware that negotiate locale and put it into assigns, so it can be read
+// by phoenix.Locale and T. A locale chosen by query param is remembered in
+// cookie.
+//
+//	GET /users?locale=zh-CN
+func SetLocale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, assigns := phoenix.EnsureAssigns(r.Context())
+		assigns.Locale = Negotiate(r)
+		if r.URL.Query().Has(LocaleParam) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     LocaleParam,
+				Value:    assigns.Locale,
+				Path:     "/",
+				MaxAge:   365 * 24 * 3600,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
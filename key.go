@@ -0,0 +1,61 @@
+package phoenix
+
+import (
+	"context"
+	"fmt"
+)
+
+// Typed keys of the id fetched from url by middleware.FetchID and its variants.
+// They share the "id" slot, so read the one of the kind the route fetched.
+var (
+	IntID    = NewKey[int]("id")    // set by middleware.FetchID
+	Int64ID  = NewKey[int64]("id")  // set by middleware.FetchInt64ID
+	StringID = NewKey[string]("id") // set by middleware.FetchUUID, FetchULID and FetchSlug
+)
+
+// Key is a typed context key, reading it never panic on missing value or
+// wrong type.
+//
+// Usage:
+//
+//	var TenantKey = phoenix.NewKey[*Tenant]("tenant")
+//
+//	ctx = TenantKey.With(ctx, tenant)
+//	tenant, ok := TenantKey.Get(ctx)
+//
+// The value is stored by CtxKey(name), so it is compatible with values set by
+// context.WithValue(ctx, phoenix.CtxKey(name), v).
+type Key[T any] struct {
+	name string
+}
+
+// Create a key with name.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// Name of the key.
+func (k Key[T]) String() string {
+	return k.name
+}
+
+// Get the value of k from ctx, ok is false when it is missing or not T.
+func (k Key[T]) Get(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(CtxKey(k.name)).(T)
+	return v, ok
+}
+
+// Get the value of k from ctx, it panics when the value is missing or not T.
+// Use it when a middleware guarantees the value, like FetchID on the route.
+func (k Key[T]) MustGet(ctx context.Context) T {
+	v, ok := k.Get(ctx)
+	if !ok {
+		panic(fmt.Sprintf("phoenix: %T value of context key %q not found, got %T", v, k.name, ctx.Value(CtxKey(k.name))))
+	}
+	return v
+}
+
+// Return a copy of ctx with v of k.
+func (k Key[T]) With(ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, CtxKey(k.name), v)
+}
@@ -0,0 +1,46 @@
+package phoenix
+
+import (
+	"context"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	ctx := IntID.With(context.Background(), 7)
+	if v, ok := IntID.Get(ctx); !ok || v != 7 {
+		t.Errorf("IntID = %v, %v", v, ok)
+	}
+	// same slot, other type
+	if v, ok := StringID.Get(ctx); ok || v != "" {
+		t.Errorf("StringID = %q, %v", v, ok)
+	}
+	if v, ok := Int64ID.Get(context.Background()); ok || v != 0 {
+		t.Errorf("missing Int64ID = %v, %v", v, ok)
+	}
+	// compatible with values set by CtxKey
+	ctx = context.WithValue(context.Background(), CtxKey("tenant"), "acme")
+	if v := NewKey[string]("tenant").MustGet(ctx); v != "acme" {
+		t.Errorf("tenant = %q", v)
+	}
+}
+
+func TestKeyMustGetPanic(t *testing.T) {
+	cases := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"missing", context.Background(), `phoenix: int value of context key "id" not found, got <nil>`},
+		{"wrong type", StringID.With(context.Background(), "a"), `phoenix: int value of context key "id" not found, got string`},
+	}
+	for _, c := range cases {
+		func() {
+			defer func() {
+				if got := recover(); got != c.want {
+					t.Errorf("%s: panic = %v, want %s", c.name, got, c.want)
+				}
+			}()
+			IntID.MustGet(c.ctx)
+		}()
+	}
+}
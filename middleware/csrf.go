@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/render"
+)
+
+const (
+	CSRFCookie = "_csrf_token"  // cookie name of csrf token
+	CSRFField  = "_csrf_token"  // form field name of csrf token
+	CSRFHeader = "X-CSRF-Token" // header name of csrf token
+)
+
+var ErrInvalidCSRFToken = errors.New("invalid csrf token")
+
+func init() {
+	phoenix.RegisterError(ErrInvalidCSRFToken, http.StatusForbidden, 0, "")
+}
+
+// Protect from cross-site request forgery by double submit cookie. The token
+// is kept in cookie and put into assigns, so it can be read by
+// phoenix.CSRFToken in templ components. Requests other than GET, HEAD,
+// OPTIONS and TRACE must submit the token by _csrf_token form field or
+// X-CSRF-Token header, otherwise it responses 403.
+//
+// The body of a multipart form is not parsed, so it can still be streamed by
+// upload.Receive, only the leading fields are peeked for the token. Put the
+// token field before file inputs, as CSRFInput in the generated forms.
+func CSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := ""
+		if c, err := r.Cookie(CSRFCookie); err == nil && len(c.Value) == 43 {
+			token = c.Value
+		} else {
+			token = newCSRFToken()
+			http.SetCookie(w, &http.Cookie{
+				Name:     CSRFCookie,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		default:
+			sent := r.Header.Get(CSRFHeader)
+			if sent == "" {
+				sent = formValue(r, CSRFField)
+			}
+			if subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+				render.Error(w, r, ErrInvalidCSRFToken)
+				return
+			}
+		}
+
+		ctx, assigns := phoenix.EnsureAssigns(r.Context())
+		assigns.CSRFToken = token
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newCSRFToken() string {
+	bs := make([]byte, 32)
+	if _, err := rand.Read(bs); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bs)
+}
+
+// Max bytes peeked from a multipart body for a form value.
+const maxPeekSize = 64 << 10
+
+// Value of form field name. A multipart body is not parsed, the value is
+// peeked from the leading non-file parts, and the body is restored for the
+// handler.
+func formValue(r *http.Request, name string) string {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return r.PostFormValue(name)
+	}
+	if r.Body == nil || params["boundary"] == "" {
+		return ""
+	}
+	var peeked bytes.Buffer
+	body := r.Body
+	defer func() {
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(&peeked, body), body}
+	}()
+	mr := multipart.NewReader(io.TeeReader(io.LimitReader(body, maxPeekSize), &peeked), params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err != nil || part.FileName() != "" {
+			return ""
+		}
+		if part.FormName() == name {
+			value, _ := io.ReadAll(io.LimitReader(part, 1024))
+			return string(value)
+		}
+	}
+}
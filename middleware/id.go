@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/go-chi/chi/v5"
+)
+
+// Kind of resource id in url.
+type IDKind int
+
+const (
+	IntID   IDKind = iota // int, read by phoenix.IntID
+	Int64ID               // int64, read by phoenix.Int64ID
+	UUID                  // lower case uuid string, read by phoenix.StringID
+	ULID                  // upper case ulid string, read by phoenix.StringID
+	Slug                  // slug string like "hello-world", read by phoenix.StringID
+)
+
+var (
+	ErrInvalidUUID = errors.New("invalid uuid")
+	ErrInvalidULID = errors.New("invalid ulid")
+	ErrInvalidSlug = errors.New("invalid slug")
+
+	uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	ulidPattern = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+	slugPattern = regexp.MustCompile(`^[a-z0-9]+(?:[-_][a-z0-9]+)*$`)
+)
+
+// Fetch url param name and put it into context by phoenix.NewKey[T](name), it
+// responses 404 when parse fails.
+//
+// Usage:
+//
+//	r.With(middleware.FetchParam("year", strconv.Atoi)).Get("/archives/{year}", ...)
+func FetchParam[T any](name string, parse func(string) (T, error)) func(next http.Handler) http.Handler {
+	key := phoenix.NewKey[T](name)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			v, err := parse(chi.URLParam(r, name))
+			if err != nil {
+				slog.Warn("fetch "+name, "error", err)
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(key.With(r.Context(), v)))
+		})
+	}
+}
+
+// Fetch id of kind with name in url, see IDKind.
+func FetchIDOf(kind IDKind, name string) func(next http.Handler) http.Handler {
+	switch kind {
+	case Int64ID:
+		return FetchParam(name, parseInt64)
+	case UUID:
+		return FetchParam(name, ParseUUID)
+	case ULID:
+		return FetchParam(name, ParseULID)
+	case Slug:
+		return FetchParam(name, ParseSlug)
+	default:
+		return FetchParam(name, strconv.Atoi)
+	}
+}
+
+// Fetch int64 id param in url, it can be read by phoenix.Int64ID.
+func FetchInt64ID(next http.Handler) http.Handler {
+	return FetchIDOf(Int64ID, "id")(next)
+}
+
+// Fetch uuid id param in url, it can be read by phoenix.StringID.
+func FetchUUID(next http.Handler) http.Handler {
+	return FetchIDOf(UUID, "id")(next)
+}
+
+// Fetch ulid id param in url, it can be read by phoenix.StringID.
+func FetchULID(next http.Handler) http.Handler {
+	return FetchIDOf(ULID, "id")(next)
+}
+
+// Fetch slug id param in url, it can be read by phoenix.StringID.
+func FetchSlug(next http.Handler) http.Handler {
+	return FetchIDOf(Slug, "id")(next)
+}
+
+func parseInt64(s string) (int64, error) {
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// Validate uuid in canonical form, it returns the lower case one.
+func ParseUUID(s string) (string, error) {
+	s = strings.ToLower(s)
+	if !uuidPattern.MatchString(s) {
+		return "", ErrInvalidUUID
+	}
+	return s, nil
+}
+
+// Validate ulid in Crockford's base32, it returns the upper case one.
+func ParseULID(s string) (string, error) {
+	s = strings.ToUpper(s)
+	if !ulidPattern.MatchString(s) {
+		return "", ErrInvalidULID
+	}
+	return s, nil
+}
+
+// Validate slug of lower case letters, digits, '-' and '_'.
+func ParseSlug(s string) (string, error) {
+	if len(s) > 255 || !slugPattern.MatchString(s) {
+		return "", ErrInvalidSlug
+	}
+	return s, nil
+}
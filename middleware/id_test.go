@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestParseID(t *testing.T) {
+	cases := []struct {
+		parse func(string) (string, error)
+		in    string
+		want  string
+		err   error
+	}{
+		{ParseUUID, "123e4567-e89b-12d3-a456-426614174000", "123e4567-e89b-12d3-a456-426614174000", nil},
+		{ParseUUID, "123E4567-E89B-12D3-A456-426614174000", "123e4567-e89b-12d3-a456-426614174000", nil},
+		{ParseUUID, "123e4567e89b12d3a456426614174000", "", ErrInvalidUUID},
+		{ParseUUID, "{123e4567-e89b-12d3-a456-426614174000}", "", ErrInvalidUUID},
+		{ParseUUID, "g23e4567-e89b-12d3-a456-426614174000", "", ErrInvalidUUID},
+		{ParseULID, "01ARZ3NDEKTSV4RRFFQ69G5FAV", "01ARZ3NDEKTSV4RRFFQ69G5FAV", nil},
+		{ParseULID, "01arz3ndektsv4rrffq69g5fav", "01ARZ3NDEKTSV4RRFFQ69G5FAV", nil},
+		{ParseULID, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", nil}, // max
+		{ParseULID, "8ZZZZZZZZZZZZZZZZZZZZZZZZZ", "", ErrInvalidULID},                // overflows 128 bits
+		{ParseULID, "01ARZ3NDEKTSV4RRFFQ69G5FA", "", ErrInvalidULID},                 // 25 chars
+		{ParseULID, "01ARZ3NDEKTSV4RRFFQ69G5FAVV", "", ErrInvalidULID},               // 27 chars
+		{ParseULID, "01ARZ3NDEKTSV4RRFFQ69G5FAI", "", ErrInvalidULID},                // I, L, O, U are excluded
+		{ParseULID, "01ARZ3NDEKTSV4RRFFQ69G5FAL", "", ErrInvalidULID},
+		{ParseULID, "01ARZ3NDEKTSV4RRFFQ69G5FAO", "", ErrInvalidULID},
+		{ParseULID, "01ARZ3NDEKTSV4RRFFQ69G5FAU", "", ErrInvalidULID},
+		{ParseSlug, "hello-world", "hello-world", nil},
+		{ParseSlug, "go_1-21", "go_1-21", nil},
+		{ParseSlug, "a", "a", nil},
+		{ParseSlug, "", "", ErrInvalidSlug},
+		{ParseSlug, "Hello", "", ErrInvalidSlug},
+		{ParseSlug, "-hello", "", ErrInvalidSlug},
+		{ParseSlug, "hello-", "", ErrInvalidSlug},
+		{ParseSlug, "hello--world", "", ErrInvalidSlug},
+		{ParseSlug, "hello world", "", ErrInvalidSlug},
+		{ParseSlug, "../etc", "", ErrInvalidSlug},
+		{ParseSlug, strings.Repeat("a", 255), strings.Repeat("a", 255), nil},
+		{ParseSlug, strings.Repeat("a", 256), "", ErrInvalidSlug},
+	}
+	for _, c := range cases {
+		got, err := c.parse(c.in)
+		if got != c.want || err != c.err {
+			t.Errorf("parse %q = %q, %v, want %q, %v", c.in, got, err, c.want, c.err)
+		}
+	}
+}
+
+func TestFetchID(t *testing.T) {
+	cases := []struct {
+		fetch func(http.Handler) http.Handler
+		path  string
+		code  int
+		body  string
+	}{
+		{FetchID, "/posts/42", http.StatusOK, "42"},
+		{FetchID, "/posts/abc", http.StatusNotFound, ""},
+		{FetchInt64ID, "/posts/9007199254740993", http.StatusOK, "9007199254740993"},
+		{FetchUUID, "/posts/123E4567-E89B-12D3-A456-426614174000", http.StatusOK, "123e4567-e89b-12d3-a456-426614174000"},
+		{FetchUUID, "/posts/42", http.StatusNotFound, ""},
+		{FetchULID, "/posts/01arz3ndektsv4rrffq69g5fav", http.StatusOK, "01ARZ3NDEKTSV4RRFFQ69G5FAV"},
+		{FetchULID, "/posts/8ZZZZZZZZZZZZZZZZZZZZZZZZZ", http.StatusNotFound, ""},
+		{FetchSlug, "/posts/hello-world", http.StatusOK, "hello-world"},
+		{FetchSlug, "/posts/Hello", http.StatusNotFound, ""},
+	}
+	for _, c := range cases {
+		r := chi.NewRouter()
+		r.With(c.fetch).Get("/posts/{id}", func(w http.ResponseWriter, r *http.Request) {
+			if v, ok := phoenix.IntID.Get(r.Context()); ok {
+				w.Write([]byte(strconv.Itoa(v)))
+			} else if v, ok := phoenix.Int64ID.Get(r.Context()); ok {
+				w.Write([]byte(strconv.FormatInt(v, 10)))
+			} else {
+				w.Write([]byte(phoenix.StringID.MustGet(r.Context())))
+			}
+		})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+		if w.Code != c.code || (c.code == http.StatusOK && w.Body.String() != c.body) {
+			t.Errorf("%s: %d %q, want %d %q", c.path, w.Code, w.Body.String(), c.code, c.body)
+		}
+	}
+}
+
+func TestFetchParam(t *testing.T) {
+	r := chi.NewRouter()
+	r.With(FetchParam("year", strconv.Atoi)).Get("/archives/{year}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strconv.Itoa(phoenix.NewKey[int]("year").MustGet(r.Context()))))
+	})
+	for path, code := range map[string]int{"/archives/2024": http.StatusOK, "/archives/last": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != code {
+			t.Errorf("%s: code = %d, want %d", path, w.Code, code)
+		}
+	}
+}
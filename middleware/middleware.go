@@ -1,22 +1,21 @@
 package middleware
 
 import (
-	"context"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/DOVECYJ/phoenix"
-	"github.com/go-chi/chi/v5"
 )
 
 // MethodSpoofing allows to spoof PUT, PATCH and DELETE methods from HTML forms, using the _method field.
 // <input type="hidden" name="_method" value="PUT">
+// A multipart body is not parsed, put _method before file inputs, see CSRF.
 func MethodSpoofing(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
-			switch method := strings.ToUpper(r.PostFormValue("_method")); method {
+			switch method := strings.ToUpper(formValue(r, "_method")); method {
 			case http.MethodPut, http.MethodPatch, http.MethodDelete:
 				r.Method = method
 			}
@@ -25,30 +24,44 @@ func MethodSpoofing(next http.Handler) http.Handler {
 	})
 }
 
-// Fetch id param in url
+// Fetch int id param in url, it can be read by phoenix.IntID. It responses
+// 404 when id is not an integer.
 func FetchID(next http.Handler) http.Handler {
+	return FetchParam("id", strconv.Atoi)(next)
+}
+
+// Fetch int id with name in url, it can be read by phoenix.NewKey[int](name).
+func FetchIDName(name string) func(next http.Handler) http.Handler {
+	return FetchParam(name, strconv.Atoi)
+}
+
+// Put a phoenix.Assigns into request context, so the following middlewares,
+// controllers and templ components can share request scoped values.
+func Assigns(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		id, err := strconv.Atoi(chi.URLParam(r, "id"))
-		if err != nil {
-			slog.Warn("fetch id", "error", err)
-			w.WriteHeader(http.StatusNotFound)
-			w.Write([]byte(err.Error()))
-			return
-		}
-		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), phoenix.ID, id)))
+		ctx, _ := phoenix.EnsureAssigns(r.Context())
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-// Fetch id with name in url
-func FetchIDName(name string) func(next http.Handler) http.Handler {
+// Load current user by load and put it into assigns, it can be read by
+// phoenix.CurrentUser. When load returns a nil user, the request goes on as
+// anonymous, or as the user already in assigns, like the one set by
+// phoenixtest. When load fails, it responses 500.
+func LoadUser(load func(r *http.Request) (any, error)) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			id, err := strconv.Atoi(chi.URLParam(r, name))
+			user, err := load(r)
 			if err != nil {
-				w.WriteHeader(http.StatusNotFound)
+				slog.Error("load user", "error", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 				return
 			}
-			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), phoenix.CtxKey(name), id)))
+			ctx, assigns := phoenix.EnsureAssigns(r.Context())
+			if user != nil {
+				assigns.CurrentUser = user
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
@@ -0,0 +1,261 @@
+// Package openapi build OpenAPI 3.1 document by walking a chi router. Routes
+// made by router.Handle are documented with their request and response
+// types, others are documented by path only.
+//
+// Request fields with path, query, header and cookie tags are parameters, the
+// rest are json body. Rules in binding tags like required, min, max and oneof
+// are converted into schema keywords. Responses are wrapped in
+// phoenix.ApiResponse as render.ApiData does, errors are problem+json.
+//
+// Usage:
+//
+//	openapi.Mount(root, openapi.Info{Title: "Blog API", Version: "1.0.0"})
+//
+// Then the document is served at /openapi.json, and the ui at /docs in dev.
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DOVECYJ/phoenix/env"
+	"github.com/DOVECYJ/phoenix/render"
+	"github.com/DOVECYJ/phoenix/router"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+const Version = "3.1.0"
+
+// Route names of the document and ui, they are not documented.
+const (
+	DocumentRoute = "openapi_path"
+	UIRoute       = "openapi_ui_path"
+)
+
+// OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Operations of a path keyed by lower case method, like "get".
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // path, query, header or cookie
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Build document of routes in r. Title of info defaults to the service name
+// in config, and version defaults to 0.0.1.
+func Build(r chi.Routes, info Info) *Document {
+	if info.Title == "" {
+		info.Title = viper.GetString("service")
+	}
+	if info.Version == "" {
+		info.Version = "0.0.1"
+	}
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+	}
+	g := newGenerator()
+	ids := map[string]bool{}
+	for _, route := range router.Routes(r) {
+		if strings.Contains(route.Pattern, "*") || route.Method == http.MethodConnect || route.Method == http.MethodTrace ||
+			route.Name == DocumentRoute || route.Name == UIRoute {
+			continue
+		}
+		path, params := convertPattern(route.Pattern)
+		op := &Operation{
+			OperationID: operationID(route, ids),
+			Tags:        tags(path),
+			Responses:   map[string]Response{},
+		}
+		for _, name := range params {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		if route.Request != nil {
+			g.request(op, route.Method, route.Request)
+		}
+		if route.Response != nil {
+			op.Responses[strconv.Itoa(route.Status)] = g.response(route.Status, route.Response)
+		} else {
+			op.Responses["200"] = Response{Description: "OK"}
+		}
+		op.Responses["default"] = Response{
+			Description: "Error",
+			Content:     map[string]MediaType{render.MIMEProblemJSON: {Schema: g.schemaOf(reflect.TypeOf(render.Problem{}))}},
+		}
+		item := doc.Paths[path]
+		if item == nil {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = op
+	}
+	doc.Components.Schemas = g.schemas
+	return doc
+}
+
+var paramRegexp = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// Convert chi pattern into OpenAPI path, "/users/{id:[0-9]+}" is "/users/{id}".
+func convertPattern(pattern string) (string, []string) {
+	var params []string
+	path := paramRegexp.ReplaceAllStringFunc(pattern, func(s string) string {
+		name := paramRegexp.FindStringSubmatch(s)[1]
+		params = append(params, name)
+		return "{" + name + "}"
+	})
+	return path, params
+}
+
+func operationID(route router.RouteInfo, ids map[string]bool) string {
+	id := route.Name
+	if id == "" || ids[id] {
+		id = route.Handler
+	}
+	if ids[id] {
+		id += "_" + strings.ToLower(route.Method)
+	}
+	ids[id] = true
+	return id
+}
+
+// Tags of path by its first segment, "/users/{id}" is users.
+func tags(path string) []string {
+	seg, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if seg == "" || strings.HasPrefix(seg, "{") {
+		return nil
+	}
+	return []string{seg}
+}
+
+//go:embed ui.html
+var uiHTML string
+
+// Script of the ui, it's a file rather than inline, so the ui works under a
+// strict Content-Security-Policy.
+//
+//go:embed ui.js
+var uiJS []byte
+
+var uiTemplate = template.Must(template.New("ui").Parse(uiHTML))
+
+// Handler serves document of r in json, the document is built on first
+// request, so all routes are set up.
+func Handler(r chi.Routes, info Info) http.Handler {
+	var (
+		once sync.Once
+		data []byte
+		err  error
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		once.Do(func() {
+			data, err = json.Marshal(Build(r, info))
+		})
+		if err != nil {
+			render.Error(w, req, err)
+			return
+		}
+		render.Bytes(w, data, render.Header("Content-Type", "application/json; charset=utf-8"))
+	})
+}
+
+// Serve document of r at /openapi.json, and the ui at /docs in dev
+// environment. Assets of the ui are embedded and served under /docs/, so it
+// works offline.
+func Mount(r chi.Router, info Info) {
+	if info.Title == "" {
+		info.Title = viper.GetString("service")
+	}
+	r.Method(http.MethodGet, "/openapi.json", router.NamedHandler(DocumentRoute, Handler(r, info)))
+	if !env.IsDev() {
+		return
+	}
+	r.Method(http.MethodGet, "/docs", router.Named(UIRoute, func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		uiTemplate.Execute(w, map[string]string{"Title": info.Title})
+	}))
+	r.Get("/docs/*", serveAsset)
+}
+
+// Serve ui.js or a file of swagger-ui-dist.
+func serveAsset(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "*")
+	if name == "ui.js" {
+		http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(uiJS))
+		return
+	}
+	f, err := swaggerFiles.FS.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	rs, ok := f.(io.ReadSeeker)
+	if err != nil || info.IsDir() || !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeContent(w, r, name, info.ModTime(), rs)
+}
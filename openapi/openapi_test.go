@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix/env"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+)
+
+func TestMountUI(t *testing.T) {
+	viper.Set("env", "dev")
+	viper.Set("service", "test")
+	if err := env.ConfigEnv(); err != nil {
+		t.Fatal(err)
+	}
+	api := chi.NewRouter()
+	Mount(api, Info{Title: "Test"})
+	r := chi.NewRouter()
+	r.Mount("/api", api)
+
+	tests := []struct {
+		path   string
+		code   int
+		ctype  string
+		substr string
+	}{
+		{"/api/docs", 200, "text/html", `href="docs/swagger-ui.css"`},
+		{"/api/docs/swagger-ui.css", 200, "text/css", ""},
+		{"/api/docs/swagger-ui-bundle.js", 200, "javascript", ""},
+		{"/api/docs/ui.js", 200, "javascript", "SwaggerUIBundle"},
+		{"/api/docs/missing.js", 404, "", ""},
+		{"/api/openapi.json", 200, "application/json", `"openapi":"3.1.0"`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.code {
+			t.Errorf("%s: code = %d, want %d", tt.path, w.Code, tt.code)
+			continue
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, tt.ctype) {
+			t.Errorf("%s: content type = %q, want %q", tt.path, ct, tt.ctype)
+		}
+		if !strings.Contains(w.Body.String(), tt.substr) {
+			t.Errorf("%s: body has no %q", tt.path, tt.substr)
+		}
+	}
+}
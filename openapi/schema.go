@@ -0,0 +1,363 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSON Schema of OpenAPI 3.1.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Default              any                `json:"default,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	rawType       = reflect.TypeOf(json.RawMessage{})
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Sources of parameters, the same as binding.BindAll.
+var sources = []string{"path", "query", "header", "cookie"}
+
+// Generator of schemas, named structs are put in components.
+type generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{schemas: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// Parameters and body of request type t.
+func (g *generator) request(op *Operation, method string, t reflect.Type) {
+	t = indirect(t)
+	if t.Kind() != reflect.Struct {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: g.schemaOf(t)}},
+		}
+		return
+	}
+	body := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.fields(t, body, func(f reflect.StructField, s *Schema, required bool) bool {
+		for _, src := range sources {
+			name, ok := f.Tag.Lookup(src)
+			if !ok {
+				continue
+			}
+			name, opts, _ := strings.Cut(name, ",")
+			if name == "" || name == "-" {
+				return true
+			}
+			if def, ok := strings.CutPrefix(opts, "default="); ok {
+				s.Default = defaultValue(s.Type, def)
+			}
+			p := Parameter{Name: name, In: src, Required: required || src == "path", Schema: s}
+			for i := range op.Parameters {
+				if op.Parameters[i].Name == name && op.Parameters[i].In == src {
+					op.Parameters[i] = p
+					return true
+				}
+			}
+			op.Parameters = append(op.Parameters, p)
+			return true
+		}
+		return false
+	})
+	if len(body.Properties) == 0 || method == http.MethodGet || method == http.MethodHead {
+		return
+	}
+	op.RequestBody = &RequestBody{
+		Required: len(body.Required) > 0,
+		Content:  map[string]MediaType{"application/json": {Schema: body}},
+	}
+}
+
+// Response of type t wrapped in phoenix.ApiResponse.
+func (g *generator) response(status int, t reflect.Type) Response {
+	res := Response{Description: http.StatusText(status)}
+	if status == http.StatusNoContent {
+		return res
+	}
+	res.Content = map[string]MediaType{"application/json": {Schema: &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code": {Type: "integer"},
+			"msg":  {Type: "string"},
+			"data": g.schemaOf(t),
+			"meta": {},
+		},
+		Required: []string{"code", "msg", "data"},
+	}}}
+	return res
+}
+
+// Schema of type t, a named struct is a reference to components.
+func (g *generator) schemaOf(t reflect.Type) *Schema {
+	t = indirect(t)
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawType:
+		return &Schema{}
+	case t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler):
+		return &Schema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = g.name(t)
+			g.names[t] = name
+			g.schemas[name] = nil // placeholder for recursive types
+			g.schemas[name] = g.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.fields(t, s, nil)
+	return s
+}
+
+// Add json fields of struct t into object s, fields taken by param are
+// skipped.
+func (g *generator) fields(t reflect.Type, s *Schema, param func(reflect.StructField, *Schema, bool) bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		tag, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && indirect(f.Type).Kind() == reflect.Struct {
+			g.fields(indirect(f.Type), s, param)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		fs := g.schemaOf(f.Type)
+		if fs.Ref == "" {
+			if strings.Contains(opts, "string") && fs.Type != "string" {
+				fs = &Schema{Type: "string"}
+			}
+			fs.Description = f.Tag.Get("description")
+		}
+		required := applyRules(fs, f.Tag.Get("binding"))
+		if param != nil && param(f, fs, required) {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = fs
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// Apply validator rules like "required,min=1,max=10" to s, returns whether the
+// field is required. Rules after dive are for elements.
+func applyRules(s *Schema, rules string) (required bool) {
+	if rules == "" {
+		return false
+	}
+	target := s
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if target.Ref != "" && name != "required" {
+			// references can't have siblings, rules of them are dropped
+			continue
+		}
+		n, err := strconv.ParseFloat(param, 64)
+		hasNum := err == nil
+		switch name {
+		case "required":
+			if target == s {
+				required = true
+			}
+		case "dive":
+			if target.Items == nil {
+				return
+			}
+			target = target.Items
+		case "min", "max", "len":
+			if !hasNum {
+				continue
+			}
+			bound(target, name, n)
+		case "gte", "gt", "lte", "lt":
+			if !hasNum || target.Type != "integer" && target.Type != "number" {
+				continue
+			}
+			switch name {
+			case "gte":
+				target.Minimum = &n
+			case "gt":
+				target.ExclusiveMinimum = &n
+			case "lte":
+				target.Maximum = &n
+			case "lt":
+				target.ExclusiveMaximum = &n
+			}
+		case "oneof":
+			for _, v := range oneofValues(param) {
+				if target.Type == "integer" || target.Type == "number" {
+					if n, err := strconv.ParseFloat(v, 64); err == nil {
+						target.Enum = append(target.Enum, n)
+						continue
+					}
+				}
+				target.Enum = append(target.Enum, v)
+			}
+		case "email", "uuid", "uri", "url", "ipv4", "ipv6", "hostname", "datetime":
+			format := name
+			switch name {
+			case "url":
+				format = "uri"
+			case "datetime":
+				format = "date-time"
+			}
+			target.Format = format
+		}
+	}
+	return
+}
+
+// Default value of param in type of schema.
+func defaultValue(typ, def string) any {
+	switch typ {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(def, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(def); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+// Set bound of rule min, max or len by type of s.
+func bound(s *Schema, rule string, n float64) {
+	i := int(n)
+	switch s.Type {
+	case "string":
+		if rule != "max" {
+			s.MinLength = &i
+		}
+		if rule != "min" {
+			s.MaxLength = &i
+		}
+	case "array":
+		if rule != "max" {
+			s.MinItems = &i
+		}
+		if rule != "min" {
+			s.MaxItems = &i
+		}
+	case "integer", "number":
+		if rule != "max" {
+			s.Minimum = &n
+		}
+		if rule != "min" {
+			s.Maximum = &n
+		}
+	}
+}
+
+var oneofRegexp = regexp.MustCompile(`'[^']*'|\S+`)
+
+// Values of oneof, a value with spaces is quoted like 'red apple'.
+func oneofValues(param string) []string {
+	values := oneofRegexp.FindAllString(param, -1)
+	for i, v := range values {
+		values[i] = strings.Trim(v, "'")
+	}
+	return values
+}
+
+var (
+	pkgPathRegexp = regexp.MustCompile(`[\w.-]*/`)
+	nameRegexp    = regexp.MustCompile(`[^\w.-]+`)
+)
+
+// Name of struct t in components, it's prefixed by package when the name is
+// taken, like "model.User". Generic "Page[x/model.Post]" is "Page_model.Post".
+func (g *generator) name(t reflect.Type) string {
+	name := pkgPathRegexp.ReplaceAllString(t.Name(), "")
+	name = strings.Trim(nameRegexp.ReplaceAllString(name, "_"), "_")
+	if _, ok := g.schemas[name]; !ok {
+		return name
+	}
+	pkg := t.PkgPath()
+	name = pkg[strings.LastIndexByte(pkg, '/')+1:] + "." + name
+	for i, n := 2, name; ; i++ {
+		if _, ok := g.schemas[n]; !ok {
+			return n
+		}
+		n = name + strconv.Itoa(i)
+	}
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,4 @@
+window.onload = function () {
+	var el = document.getElementById("swagger-ui");
+	window.ui = SwaggerUIBundle({ url: el.dataset.url, domElement: el });
+};
@@ -0,0 +1,60 @@
+package paginate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+const (
+	next = "n"
+	prev = "p"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor point to a record by the value of key column. It is encoded as
+// base64 url encoding of json, clients should treat it as opaque string.
+type cursor struct {
+	Key       any    `json:"k"`
+	Direction string `json:"d"`
+}
+
+func (c cursor) encode() string {
+	bs, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(bs)
+}
+
+func decodeCursor(s string) (c cursor, err error) {
+	bs, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	var raw struct {
+		Key       json.RawMessage `json:"k"`
+		Direction string          `json:"d"`
+	}
+	if err = json.Unmarshal(bs, &raw); err != nil || len(raw.Key) == 0 {
+		return c, ErrInvalidCursor
+	}
+	if raw.Direction != next && raw.Direction != prev {
+		return c, ErrInvalidCursor
+	}
+	c.Direction = raw.Direction
+	// keep integer key as integer, so it compares right in database
+	if n, err := strconv.ParseInt(string(raw.Key), 10, 64); err == nil {
+		c.Key = n
+		return c, nil
+	}
+	var key any
+	if err = json.Unmarshal(raw.Key, &key); err != nil {
+		return c, ErrInvalidCursor
+	}
+	switch key.(type) {
+	case string, float64:
+		c.Key = key
+		return c, nil
+	}
+	return c, ErrInvalidCursor
+}
@@ -0,0 +1,299 @@
+// Package paginate provide offset and cursor paging from http request query
+// to rel queries and api response.
+//
+// Offset paging use query string page and page_size:
+//
+//	GET /users?page=2&page_size=20
+//
+// Cursor(keyset) paging use query string cursor and limit:
+//
+//	GET /users?limit=20
+//	GET /users?cursor=eyJrIjoyMCwiZCI6Im4ifQ&limit=20
+package paginate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/render"
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+)
+
+var (
+	DefaultPageSize = 20  // page size when it is not specified
+	MaxPageSize     = 100 // page size larger than it will be truncated
+)
+
+// Paging mode
+type Mode int
+
+const (
+	Offset Mode = iota // page and page_size
+	Cursor             // cursor and limit
+)
+
+// Params of paging, usually read from request by FromRequest.
+type Params struct {
+	Mode     Mode
+	Page     int    // page number start from 1, offset mode only
+	PageSize int    // page size, it's the limit in cursor mode
+	Cursor   string // cursor mode only, empty means the first page
+	Key      string // column for keyset paging, default is "id"
+}
+
+// Read paging params from query string of r. When cursor or limit is present
+// it will be cursor mode, otherwise offset mode. Invalid params will return
+// phoenix.FieldError.
+func FromRequest(r *http.Request) (Params, error) {
+	return FromQuery(r.URL.Query())
+}
+
+// Read paging params from query values.
+func FromQuery(query url.Values) (Params, error) {
+	p := Params{Page: 1, PageSize: DefaultPageSize, Key: "id"}
+	ferr := phoenix.FieldError{}
+	if query.Has("cursor") || query.Has("limit") {
+		p.Mode = Cursor
+		p.Cursor = query.Get("cursor")
+		if p.Cursor != "" {
+			if _, err := decodeCursor(p.Cursor); err != nil {
+				ferr["cursor"] = err
+			}
+		}
+		p.PageSize = positive(query, "limit", p.PageSize, ferr)
+	} else {
+		p.Page = positive(query, "page", p.Page, ferr)
+		p.PageSize = positive(query, "page_size", p.PageSize, ferr)
+	}
+	if len(ferr) > 0 {
+		return p, ferr
+	}
+	p.PageSize = min(p.PageSize, MaxPageSize)
+	return p, nil
+}
+
+func positive(query url.Values, key string, or int, ferr phoenix.FieldError) int {
+	s := query.Get(key)
+	if s == "" {
+		return or
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		ferr[key] = errors.New("must be a positive integer")
+		return or
+	}
+	return n
+}
+
+// Metadata of a page, it is rendered as the meta of ApiResponse.
+type Meta struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      int    `json:"total,omitempty"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	mode       Mode
+}
+
+// A page of items with paging metadata.
+type Page[T any] struct {
+	Items []T
+	Meta
+}
+
+func (m Meta) HasNext() bool {
+	if m.mode == Cursor {
+		return m.NextCursor != ""
+	}
+	return m.Page < m.TotalPages
+}
+
+func (m Meta) HasPrev() bool {
+	if m.mode == Cursor {
+		return m.PrevCursor != ""
+	}
+	return m.Page > 1
+}
+
+// Query string of next page, base is the current query which can be nil.
+func (m Meta) NextQuery(base url.Values) string {
+	if m.mode == Cursor {
+		return m.cursorQuery(base, m.NextCursor)
+	}
+	return m.PageQuery(base, m.Page+1)
+}
+
+// Query string of previous page, base is the current query which can be nil.
+func (m Meta) PrevQuery(base url.Values) string {
+	if m.mode == Cursor {
+		return m.cursorQuery(base, m.PrevCursor)
+	}
+	return m.PageQuery(base, m.Page-1)
+}
+
+// Query string of page n in offset mode, base is the current query which can
+// be nil.
+func (m Meta) PageQuery(base url.Values, n int) string {
+	q := cloneQuery(base)
+	q.Set("page", strconv.Itoa(n))
+	q.Set("page_size", strconv.Itoa(m.PageSize))
+	return "?" + q.Encode()
+}
+
+func (m Meta) cursorQuery(base url.Values, cursor string) string {
+	q := cloneQuery(base)
+	q.Set("cursor", cursor)
+	q.Set("limit", strconv.Itoa(m.PageSize))
+	return "?" + q.Encode()
+}
+
+// Page numbers around current page for pagination component, at most size
+// numbers will be returned.
+func (m Meta) PageNumbers(size int) []int {
+	if m.mode == Cursor || m.TotalPages == 0 {
+		return nil
+	}
+	start := max(1, m.Page-size/2)
+	end := min(m.TotalPages, start+size-1)
+	start = max(1, end-size+1)
+	numbers := make([]int, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		numbers = append(numbers, i)
+	}
+	return numbers
+}
+
+// Build the value of Link header (RFC 8288) for u.
+func (m Meta) Links(u *url.URL) string {
+	var links []string
+	link := func(query, rel string) {
+		links = append(links, fmt.Sprintf(`<%s%s>; rel="%s"`, u.Path, query, rel))
+	}
+	base := u.Query()
+	if m.mode == Offset && m.TotalPages > 0 {
+		link(m.PageQuery(base, 1), "first")
+	}
+	if m.HasPrev() {
+		link(m.PrevQuery(base), "prev")
+	}
+	if m.HasNext() {
+		link(m.NextQuery(base), "next")
+	}
+	if m.mode == Offset && m.TotalPages > 0 {
+		link(m.PageQuery(base, m.TotalPages), "last")
+	}
+	return strings.Join(links, ", ")
+}
+
+func cloneQuery(q url.Values) url.Values {
+	c := url.Values{}
+	for k, v := range q {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// Find a page of T in repo by p. The queriers is used to filter records, in
+// cursor mode the sorting is replaced by the key column.
+//
+// Usage:
+//
+//	p, err := paginate.FromRequest(r)
+//	page, err := paginate.Find[model.User](ctx, repo.Repo, p, where.Eq("active", true))
+func Find[T any](ctx context.Context, repo rel.Repository, p Params, queriers ...rel.Querier) (page Page[T], err error) {
+	if p.PageSize < 1 {
+		p.PageSize = DefaultPageSize
+	}
+	if p.Key == "" {
+		p.Key = "id"
+	}
+	var zero T
+	query := rel.Build(rel.NewDocument(&zero, true).Table(), queriers...)
+	if p.Mode == Cursor {
+		return findCursor[T](ctx, repo, p, query)
+	}
+	return findOffset[T](ctx, repo, p, query)
+}
+
+func findOffset[T any](ctx context.Context, repo rel.Repository, p Params, query rel.Query) (page Page[T], err error) {
+	page.mode = Offset
+	page.Page = max(p.Page, 1)
+	page.PageSize = p.PageSize
+	if page.Total, err = repo.Aggregate(ctx, query, "count", "*"); err != nil {
+		return
+	}
+	page.TotalPages = (page.Total + p.PageSize - 1) / p.PageSize
+	page.Items = []T{}
+	err = repo.FindAll(ctx, &page.Items, query.Offset((page.Page-1)*p.PageSize).Limit(p.PageSize))
+	return
+}
+
+func findCursor[T any](ctx context.Context, repo rel.Repository, p Params, query rel.Query) (page Page[T], err error) {
+	page.mode = Cursor
+	page.PageSize = p.PageSize
+	query.SortQuery = nil
+
+	c := cursor{Direction: next}
+	if p.Cursor != "" {
+		if c, err = decodeCursor(p.Cursor); err != nil {
+			return page, phoenix.FieldError{"cursor": err}
+		}
+		if c.Direction == prev {
+			query = query.Where(where.Lt(p.Key, c.Key)).SortDesc(p.Key)
+		} else {
+			query = query.Where(where.Gt(p.Key, c.Key)).SortAsc(p.Key)
+		}
+	} else {
+		query = query.SortAsc(p.Key)
+	}
+
+	page.Items = []T{}
+	if err = repo.FindAll(ctx, &page.Items, query.Limit(p.PageSize+1)); err != nil {
+		return
+	}
+	more := len(page.Items) > p.PageSize
+	if more {
+		page.Items = page.Items[:p.PageSize]
+	}
+	if c.Direction == prev {
+		for i, j := 0, len(page.Items)-1; i < j; i, j = i+1, j-1 {
+			page.Items[i], page.Items[j] = page.Items[j], page.Items[i]
+		}
+	}
+	if len(page.Items) == 0 {
+		return
+	}
+	first, last := keyOf(&page.Items[0], p.Key), keyOf(&page.Items[len(page.Items)-1], p.Key)
+	hasNext, hasPrev := more, p.Cursor != ""
+	if c.Direction == prev {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		page.NextCursor = cursor{Key: last, Direction: next}.encode()
+	}
+	if hasPrev {
+		page.PrevCursor = cursor{Key: first, Direction: prev}.encode()
+	}
+	return
+}
+
+func keyOf(entity any, key string) any {
+	v, _ := rel.NewDocument(entity, true).Value(key)
+	return v
+}
+
+// Render page to w as ApiResponse with meta, and the Link header is set.
+func Render[T any](w http.ResponseWriter, r *http.Request, page Page[T], opts ...render.Option) {
+	if links := page.Links(r.URL); links != "" {
+		opts = append(opts, render.Header("Link", links))
+	}
+	render.JSON(w, phoenix.ApiResponse{Data: page.Items, Meta: page.Meta}, opts...)
+}
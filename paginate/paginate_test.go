@@ -0,0 +1,147 @@
+package paginate
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/go-rel/rel"
+	"github.com/go-rel/sqlite3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestFromQuery(t *testing.T) {
+	cases := []struct {
+		query string
+		want  Params
+		errs  []string
+	}{
+		{"", Params{Mode: Offset, Page: 1, PageSize: DefaultPageSize, Key: "id"}, nil},
+		{"page=3&page_size=10", Params{Mode: Offset, Page: 3, PageSize: 10, Key: "id"}, nil},
+		{"page_size=1000", Params{Mode: Offset, Page: 1, PageSize: MaxPageSize, Key: "id"}, nil},
+		{"limit=5", Params{Mode: Cursor, Page: 1, PageSize: 5, Key: "id"}, nil},
+		{"cursor=" + cursor{Key: 9, Direction: next}.encode(), Params{Mode: Cursor, Page: 1, PageSize: DefaultPageSize, Cursor: cursor{Key: 9, Direction: next}.encode(), Key: "id"}, nil},
+		{"page=0&page_size=x", Params{}, []string{"page", "page_size"}},
+		{"cursor=bad&limit=-1", Params{}, []string{"cursor", "limit"}},
+	}
+	for _, c := range cases {
+		values, _ := url.ParseQuery(c.query)
+		p, err := FromQuery(values)
+		if c.errs != nil {
+			var ferr phoenix.FieldError
+			if !errors.As(err, &ferr) || len(ferr) != len(c.errs) {
+				t.Errorf("%s: err = %v, want errors of %v", c.query, err, c.errs)
+				continue
+			}
+			for _, key := range c.errs {
+				if ferr[key] == nil {
+					t.Errorf("%s: no error of %s", c.query, key)
+				}
+			}
+			continue
+		}
+		if err != nil || p != c.want {
+			t.Errorf("%s: got %+v, %v, want %+v", c.query, p, err, c.want)
+		}
+	}
+}
+
+func TestCursor(t *testing.T) {
+	for _, c := range []cursor{{Key: int64(42), Direction: next}, {Key: "abc", Direction: prev}, {Key: 1.5, Direction: next}} {
+		got, err := decodeCursor(c.encode())
+		if err != nil || !reflect.DeepEqual(got, c) {
+			t.Errorf("%+v: got %+v, %v", c, got, err)
+		}
+	}
+	encode := base64.RawURLEncoding.EncodeToString
+	for _, s := range []string{"!", encode([]byte(`{"d":"n"}`)), encode([]byte(`{"k":1,"d":"x"}`)), encode([]byte(`{"k":[1],"d":"n"}`)), encode([]byte(`[]`))} {
+		if _, err := decodeCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%q: err = %v", s, err)
+		}
+	}
+}
+
+type post struct {
+	ID    int
+	Title string
+}
+
+func TestFind(t *testing.T) {
+	adapter, err := sqlite3.Open("file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer adapter.Close()
+	ctx := context.Background()
+	if _, _, err := adapter.Exec(ctx, "CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT)", nil); err != nil {
+		t.Fatal(err)
+	}
+	repo := rel.New(adapter)
+	for i := 1; i <= 5; i++ {
+		repo.MustInsert(ctx, &post{ID: i, Title: fmt.Sprint("post ", i)})
+	}
+	ids := func(page Page[post]) string {
+		var s []string
+		for _, p := range page.Items {
+			s = append(s, fmt.Sprint(p.ID))
+		}
+		return strings.Join(s, ",")
+	}
+
+	page, err := Find[post](ctx, repo, Params{Mode: Offset, Page: 2, PageSize: 2}, rel.SortAsc("id"))
+	if err != nil || ids(page) != "3,4" || page.Total != 5 || page.TotalPages != 3 {
+		t.Fatalf("offset: %s %+v %v", ids(page), page.Meta, err)
+	}
+
+	// walk forward then back, the sorting of queriers is replaced by key
+	walk := []struct {
+		cursor     func(Meta) string
+		want       string
+		next, prev bool
+	}{
+		{func(Meta) string { return "" }, "1,2", true, false},
+		{func(m Meta) string { return m.NextCursor }, "3,4", true, true},
+		{func(m Meta) string { return m.NextCursor }, "5", false, true},
+		{func(m Meta) string { return m.PrevCursor }, "3,4", true, true},
+		{func(m Meta) string { return m.PrevCursor }, "1,2", true, false},
+	}
+	var meta Meta
+	for i, step := range walk {
+		page, err := Find[post](ctx, repo, Params{Mode: Cursor, PageSize: 2, Cursor: step.cursor(meta)}, rel.SortDesc("title"))
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if ids(page) != step.want || page.HasNext() != step.next || page.HasPrev() != step.prev {
+			t.Fatalf("step %d: %s next=%v prev=%v, want %s next=%v prev=%v",
+				i, ids(page), page.HasNext(), page.HasPrev(), step.want, step.next, step.prev)
+		}
+		meta = page.Meta
+	}
+}
+
+func TestLinks(t *testing.T) {
+	u, _ := url.Parse("/posts?q=go&page=2&page_size=10")
+	m := Meta{Page: 2, PageSize: 10, Total: 35, TotalPages: 4}
+	want := `</posts?page=1&page_size=10&q=go>; rel="first", ` +
+		`</posts?page=1&page_size=10&q=go>; rel="prev", ` +
+		`</posts?page=3&page_size=10&q=go>; rel="next", ` +
+		`</posts?page=4&page_size=10&q=go>; rel="last"`
+	if got := m.Links(u); got != want {
+		t.Errorf("offset links:\n got %s\nwant %s", got, want)
+	}
+
+	u, _ = url.Parse("/posts?q=go&limit=10")
+	m = Meta{PageSize: 10, NextCursor: "abc", mode: Cursor}
+	if got, want := m.Links(u), `</posts?cursor=abc&limit=10&q=go>; rel="next"`; got != want {
+		t.Errorf("cursor links:\n got %s\nwant %s", got, want)
+	}
+	if got := (Meta{Page: 1, PageSize: 10}).Links(u); got != "" {
+		t.Errorf("empty links: %s", got)
+	}
+}
@@ -1,6 +1,8 @@
 package phoenix
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -76,6 +78,7 @@ func MustWritePID(name string) {
 	}
 }
 
+// Errors of each field, the key is the field name on the wire.
 type FieldError map[string]error
 
 func (e FieldError) Error() string {
@@ -89,6 +92,48 @@ func (e FieldError) Error() string {
 	return sb.String()
 }
 
+// Marshal each error as a ValidationError.
+//
+//	{"name": {"code": "required", "message": "Name is required"}}
+func (e FieldError) MarshalJSON() ([]byte, error) {
+	m := make(map[string]ValidationError, len(e))
+	for k, v := range e {
+		m[k] = AsValidationError(v)
+	}
+	return json.Marshal(m)
+}
+
+// ValidationError is a machine readable error of one field. Code is the
+// validation failed like required and min, Params are its arguments like
+// {"param": "3"}.
+type ValidationError struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Params  map[string]any `json:"params,omitempty"`
+	Err     error          `json:"-"` // the original error
+}
+
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Convert err into ValidationError, an error that is not ValidationError gets
+// code invalid.
+func AsValidationError(err error) ValidationError {
+	var ve ValidationError
+	if errors.As(err, &ve) {
+		return ve
+	}
+	if err == nil {
+		return ValidationError{Code: "invalid"}
+	}
+	return ValidationError{Code: "invalid", Message: err.Error(), Err: err}
+}
+
 func ExtractChangesetError(c *changeset.Changeset) FieldError {
 	if c == nil || c.Error() == nil {
 		return nil
@@ -96,7 +141,8 @@ func ExtractChangesetError(c *changeset.Changeset) FieldError {
 	err := FieldError{}
 	for _, e := range c.Errors() {
 		if e, ok := e.(changeset.Error); ok {
-			err[e.Field] = e.Err
+			// keep the changeset.Error, its Err is nil for validation errors
+			err[e.Field] = e
 		}
 	}
 	return err
@@ -0,0 +1,379 @@
+// Package phoenixtest helps to test routers, plugs and controllers. A request
+// is built with params, cookies, assigns and user, then it's run through a
+// chi router or plug endpoint, a plug, or an action of a controller, the
+// response is checked by fluent assertions.
+//
+// Usage:
+//
+//	func TestShowUser(t *testing.T) {
+//		phoenixtest.Get(t, "/users/1").
+//			ID(1).
+//			User(&model.User{ID: 1}).
+//			Action(controllers.UserController{}, "show").
+//			Status(http.StatusOK).
+//			HTML("h1", "Bob")
+//	}
+//
+//	func TestCreatePost(t *testing.T) {
+//		s := phoenixtest.NewSession(t, NewRouter())
+//		s.Post("/posts").CSRF().Form("title", "hello").Send().
+//			Redirect("/posts/1").
+//			Follow().
+//			Flash(flash.Info, "Post created successfully.").
+//			HTML(".post h1", "hello")
+//	}
+//
+// Assertions report by t.Errorf and go on, so one test can check many things.
+package phoenixtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/flash"
+	"github.com/DOVECYJ/phoenix/middleware"
+	"github.com/DOVECYJ/phoenix/plug"
+	"github.com/DOVECYJ/phoenix/router"
+	"github.com/go-chi/chi/v5"
+)
+
+// Token used by Request.CSRF when the session has none.
+const csrfToken = "phoenixtest-csrf-token-0123456789abcdefghij"
+
+// Request builder.
+type Request struct {
+	t       testing.TB
+	method  string
+	target  string
+	query   url.Values
+	form    url.Values
+	body    []byte
+	ctype   string
+	header  http.Header
+	cookies []*http.Cookie
+	params  []param
+	assigns *phoenix.Assigns
+	csrf    bool
+	session *Session
+}
+
+type param struct {
+	name  string
+	value any
+}
+
+// New request of method to target, target is a path with optional query like
+// "/users?page=2".
+func NewRequest(t testing.TB, method, target string) *Request {
+	return &Request{
+		t:       t,
+		method:  method,
+		target:  target,
+		query:   url.Values{},
+		form:    url.Values{},
+		header:  http.Header{},
+		assigns: &phoenix.Assigns{},
+	}
+}
+
+func Get(t testing.TB, target string) *Request {
+	return NewRequest(t, http.MethodGet, target)
+}
+
+func Post(t testing.TB, target string) *Request {
+	return NewRequest(t, http.MethodPost, target)
+}
+
+func Put(t testing.TB, target string) *Request {
+	return NewRequest(t, http.MethodPut, target)
+}
+
+func Patch(t testing.TB, target string) *Request {
+	return NewRequest(t, http.MethodPatch, target)
+}
+
+func Delete(t testing.TB, target string) *Request {
+	return NewRequest(t, http.MethodDelete, target)
+}
+
+// Add a query param.
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Add a form param, the body is url encoded form.
+func (r *Request) Form(key, value string) *Request {
+	r.form.Add(key, value)
+	return r
+}
+
+// Set body to v in json.
+func (r *Request) JSON(v any) *Request {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		r.t.Fatalf("phoenixtest: encode json: %v", err)
+	}
+	return r.Body("application/json", bs)
+}
+
+// Set body of content type.
+func (r *Request) Body(contentType string, body []byte) *Request {
+	r.ctype, r.body = contentType, body
+	return r
+}
+
+func (r *Request) Header(key, value string) *Request {
+	r.header.Add(key, value)
+	return r
+}
+
+func (r *Request) Cookie(name, value string) *Request {
+	r.cookies = append(r.cookies, &http.Cookie{Name: name, Value: value})
+	return r
+}
+
+// Set url param name, like {slug}, it's also put into context by
+// phoenix.NewKey[T](name) as the type of value, as middleware.FetchParam
+// does. It's needed when a plug or action is run directly, a router fetches
+// params from the path itself.
+func (r *Request) Param(name string, value any) *Request {
+	r.params = append(r.params, param{name, value})
+	return r
+}
+
+// Set id param, it can be read by phoenix.IntID, phoenix.Int64ID or
+// phoenix.StringID as the type of id.
+func (r *Request) ID(id any) *Request {
+	return r.Param("id", id)
+}
+
+// Assign a value.
+func (r *Request) Assign(key string, val any) *Request {
+	r.assigns.Set(key, val)
+	return r
+}
+
+// Authenticate as user, it can be read by phoenix.CurrentUser.
+func (r *Request) User(user any) *Request {
+	r.assigns.CurrentUser = user
+	return r
+}
+
+// Put an incoming flash message, like the one put before redirect.
+func (r *Request) Flash(kind, msg string) *Request {
+	if r.assigns.Flash == nil {
+		r.assigns.Flash = map[string]string{}
+	}
+	r.assigns.Flash[kind] = msg
+	return r
+}
+
+// Submit csrf token, so the request passes middleware.CSRF.
+func (r *Request) CSRF() *Request {
+	r.csrf = true
+	return r
+}
+
+// Build the http request, assigns are put in its context.
+func (r *Request) Build() *http.Request {
+	return r.build(false)
+}
+
+// Url params are put in route context when route is true, for handlers run
+// without a router.
+func (r *Request) build(route bool) *http.Request {
+	target := r.target
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + r.query.Encode()
+	}
+	var body io.Reader
+	ctype := r.ctype
+	switch {
+	case r.body != nil:
+		body = bytes.NewReader(r.body)
+	case len(r.form) > 0:
+		body = strings.NewReader(r.form.Encode())
+		ctype = "application/x-www-form-urlencoded"
+	}
+	req := httptest.NewRequest(r.method, target, body)
+	if ctype != "" {
+		req.Header.Set("Content-Type", ctype)
+	}
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	cookies := r.cookies
+	if r.session != nil {
+		cookies = append(r.session.list(), cookies...)
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	if r.csrf {
+		token := csrfToken
+		if c, err := req.Cookie(middleware.CSRFCookie); err == nil {
+			token = c.Value
+		} else {
+			req.AddCookie(&http.Cookie{Name: middleware.CSRFCookie, Value: token})
+		}
+		req.Header.Set(middleware.CSRFHeader, token)
+	}
+
+	ctx := phoenix.WithAssigns(req.Context(), r.assigns)
+	if route {
+		rctx := chi.NewRouteContext()
+		for _, p := range r.params {
+			rctx.URLParams.Add(p.name, fmt.Sprint(p.value))
+			ctx = withParam(ctx, p)
+		}
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	}
+	return req.WithContext(ctx)
+}
+
+func withParam(ctx context.Context, p param) context.Context {
+	switch v := p.value.(type) {
+	case int:
+		return phoenix.NewKey[int](p.name).With(ctx, v)
+	case int64:
+		return phoenix.NewKey[int64](p.name).With(ctx, v)
+	case string:
+		return phoenix.NewKey[string](p.name).With(ctx, v)
+	}
+	return ctx
+}
+
+// Serve the request by h, like a chi router, plug.Router or plug.Endpoint.
+func (r *Request) Serve(h http.Handler) *Response {
+	r.t.Helper()
+	return r.serve(h, r.build(false))
+}
+
+// Run plug p with the request, url params are set by Param.
+func (r *Request) Plug(p interface{ Handle(*plug.Conn) }) *Response {
+	r.t.Helper()
+	return r.serve(flash.Fetch(plug.NewEndpoint(p)), r.build(true))
+}
+
+// Run action of rsc, like "show", url params are set by Param and ID. rsc can
+// be a router.IResource or plug.Controller.
+func (r *Request) Action(rsc any, action string) *Response {
+	r.t.Helper()
+	var res router.IResource
+	switch rsc := rsc.(type) {
+	case router.IResource:
+		res = rsc
+	case plug.Controller:
+		res = plug.AsResource(rsc)
+	default:
+		r.t.Fatalf("phoenixtest: %T is not a controller", rsc)
+	}
+	actions := map[string]http.HandlerFunc{
+		"index":  res.Index,
+		"edit":   res.Edit,
+		"new":    res.New,
+		"show":   res.Show,
+		"create": res.Create,
+		"update": res.Update,
+		"delete": res.Delete,
+	}
+	h, ok := actions[action]
+	if !ok {
+		r.t.Fatalf("phoenixtest: unknown action %q", action)
+	}
+	return r.serve(flash.Fetch(h), r.build(true))
+}
+
+// Send the request in its session.
+func (r *Request) Send() *Response {
+	r.t.Helper()
+	if r.session == nil {
+		r.t.Fatalf("phoenixtest: request %s %s is not in a session", r.method, r.target)
+	}
+	res := r.Serve(r.session.h)
+	r.session.keep(res.Result().Cookies())
+	return res
+}
+
+func (r *Request) serve(h http.Handler, req *http.Request) *Response {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return &Response{ResponseRecorder: w, t: r.t, assigns: r.assigns, h: h, session: r.session}
+}
+
+// Session sends requests to a handler with cookies of previous responses, like
+// a browser.
+type Session struct {
+	t       testing.TB
+	h       http.Handler
+	cookies map[string]*http.Cookie
+}
+
+func NewSession(t testing.TB, h http.Handler) *Session {
+	return &Session{t: t, h: h, cookies: map[string]*http.Cookie{}}
+}
+
+// New request in the session, send it by Send.
+func (s *Session) Request(method, target string) *Request {
+	r := NewRequest(s.t, method, target)
+	r.session = s
+	return r
+}
+
+func (s *Session) Get(target string) *Request {
+	return s.Request(http.MethodGet, target)
+}
+
+func (s *Session) Post(target string) *Request {
+	return s.Request(http.MethodPost, target)
+}
+
+func (s *Session) Put(target string) *Request {
+	return s.Request(http.MethodPut, target)
+}
+
+func (s *Session) Patch(target string) *Request {
+	return s.Request(http.MethodPatch, target)
+}
+
+func (s *Session) Delete(target string) *Request {
+	return s.Request(http.MethodDelete, target)
+}
+
+// Cookie of name in the session.
+func (s *Session) Cookie(name string) (*http.Cookie, bool) {
+	c, ok := s.cookies[name]
+	return c, ok
+}
+
+func (s *Session) keep(cookies []*http.Cookie) {
+	for _, c := range cookies {
+		if c.MaxAge < 0 {
+			delete(s.cookies, c.Name)
+			continue
+		}
+		s.cookies[c.Name] = c
+	}
+}
+
+func (s *Session) list() []*http.Cookie {
+	cookies := make([]*http.Cookie, 0, len(s.cookies))
+	for _, c := range s.cookies {
+		cookies = append(cookies, &http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return cookies
+}
@@ -0,0 +1,152 @@
+package phoenixtest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/flash"
+	"github.com/DOVECYJ/phoenix/middleware"
+	"github.com/DOVECYJ/phoenix/plug"
+	"github.com/DOVECYJ/phoenix/render"
+	"github.com/DOVECYJ/phoenix/router"
+	"github.com/go-chi/chi/v5"
+)
+
+type UserController struct{}
+
+func (UserController) Index(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, map[string]any{"users": []map[string]any{{"id": 1, "name": "bob"}}, "page": r.URL.Query().Get("page")})
+}
+func (UserController) Edit(w http.ResponseWriter, r *http.Request) {}
+func (UserController) New(w http.ResponseWriter, r *http.Request)  {}
+func (UserController) Show(w http.ResponseWriter, r *http.Request) {
+	user, _ := phoenix.CurrentUserAs[string](r.Context())
+	fmt.Fprintf(w, `<ul id="users"><li class="user current"><a href="/users/%d">%s</a></li></ul><p>%s</p>`,
+		phoenix.IntID.MustGet(r.Context()), user, phoenix.Flash(r.Context())[flash.Info])
+}
+func (UserController) Create(w http.ResponseWriter, r *http.Request) {
+	flash.Put(w, r, flash.Info, "created "+r.PostFormValue("name"))
+	http.Redirect(w, r, "/users/2", http.StatusFound)
+}
+func (UserController) Update(w http.ResponseWriter, r *http.Request) {}
+func (UserController) Delete(w http.ResponseWriter, r *http.Request) {}
+
+type PostController struct{}
+
+func (PostController) Index(c *plug.Conn) {}
+func (PostController) Edit(c *plug.Conn)  {}
+func (PostController) New(c *plug.Conn)   {}
+func (PostController) Show(c *plug.Conn) {
+	id, _ := phoenix.NewKey[string]("id").Get(c.Context())
+	who, _ := c.Assigned("who")
+	c.JSON(map[string]any{"id": id, "who": who})
+}
+func (PostController) Create(c *plug.Conn) {}
+func (PostController) Update(c *plug.Conn) {}
+func (PostController) Delete(c *plug.Conn) {}
+
+// recorder records failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRouter(t *testing.T) {
+	flash.SetSecret([]byte("test secret"))
+	r := chi.NewRouter()
+	r.Use(middleware.Assigns, flash.Fetch, middleware.CSRF)
+	r.Route("/users", router.Resource(UserController{}))
+
+	Get(t, "/users").Query("page", "2").Serve(r).
+		Status(http.StatusOK).
+		JSON("users[0].name", "bob").
+		JSON("$.users.0.id", 1).
+		JSON("page", "2")
+
+	s := NewSession(t, r)
+	s.Get("/users/1").User("bob").Send().
+		Status(http.StatusOK).
+		HTML("ul#users > li.user.current a[href='/users/1']", "bob").
+		HTMLCount("li", 1)
+	if _, ok := s.Cookie(middleware.CSRFCookie); !ok {
+		t.Fatal("csrf cookie is not kept in session")
+	}
+	s.Post("/users").CSRF().Form("name", "amy").Send().
+		Redirect("/users/2").
+		Flash(flash.Info, "created amy").
+		Follow().
+		Status(http.StatusOK).
+		HTML("p", "created amy")
+
+	Post(t, "/users").Form("name", "amy").Serve(r).Status(http.StatusForbidden)
+}
+
+func TestAction(t *testing.T) {
+	Get(t, "/users/3").ID(3).User("bob").Flash(flash.Info, "hi").Action(UserController{}, "show").
+		Status(http.StatusOK).
+		HTML("a", "bob").
+		HTML("p", "hi")
+
+	Get(t, "/posts/hello").ID("hello").Assign("who", "me").Action(PostController{}, "show").
+		JSON("id", "hello").
+		JSON("who", "me")
+}
+
+func TestPlug(t *testing.T) {
+	who := plug.PlugFunc(func(c *plug.Conn) { c.Assign("who", "me") })
+	r := plug.NewRouter(
+		plug.PipeThrough(who),
+		plug.Resource("/posts", PostController{}, router.WithID(middleware.Slug), router.Except("delete"),
+			plug.Get("/author", plug.PlugFunc(func(c *plug.Conn) {
+				c.Text(phoenix.NewKey[string]("post_id").MustGet(c.Context()))
+			})),
+		),
+	)
+	Get(t, "/posts/hello").Serve(r).JSON("who", "me").JSON("id", "hello")
+	Get(t, "/posts/hello/author").Serve(r).Status(http.StatusOK).Contains("hello")
+	Get(t, "/posts/Hello").Serve(r).Status(http.StatusNotFound)
+	Delete(t, "/posts/hello").Serve(r).Status(http.StatusMethodNotAllowed)
+
+	Get(t, "/posts/hello").Param("id", "hello").Plug(plug.PlugFunc(func(c *plug.Conn) {
+		c.Text(c.Param("id"))
+	})).Status(http.StatusOK).Contains("hello")
+
+	Post(t, "/posts/hello?id=query&page=2").Param("id", "hello").Form("title", "hi").Form("page", "3").Plug(plug.PlugFunc(func(c *plug.Conn) {
+		params, err := c.Params()
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(map[string]any{"id": params.Get("id"), "title": params.Get("title"), "page": params.Get("page"), "param": c.Param("title")})
+	})).JSON("id", "hello").JSON("title", "hi").JSON("page", "3").JSON("param", "")
+}
+
+func TestFailures(t *testing.T) {
+	rec := &recorder{TB: t}
+	Get(rec, "/users/3").ID(3).Action(UserController{}, "show").
+		Status(http.StatusCreated).
+		HTML("li.admin", "").
+		HTML("a", "amy").
+		HTMLCount("li", 2).
+		Redirect("/").
+		Flash(flash.Info, "hi").
+		JSON("id", 3)
+	want := []string{"status", "li.admin", "want text", "want 2", "redirect", "flash", "json"}
+	if len(rec.errors) != len(want) {
+		t.Fatalf("want %d errors, got: %q", len(want), rec.errors)
+	}
+	for i, w := range want {
+		if !strings.Contains(rec.errors[i], w) {
+			t.Errorf("error %d: want %q, got %q", i, w, rec.errors[i])
+		}
+	}
+}
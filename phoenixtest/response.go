@@ -0,0 +1,232 @@
+package phoenixtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix"
+	"golang.org/x/net/html"
+)
+
+// Response of a request with assertions.
+type Response struct {
+	*httptest.ResponseRecorder
+	t       testing.TB
+	assigns *phoenix.Assigns
+	h       http.Handler // for Follow
+	session *Session
+	doc     *html.Node
+}
+
+// Assigns of the request, with values put by the handler.
+func (r *Response) Assigns() *phoenix.Assigns {
+	return r.assigns
+}
+
+// Assert status code.
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	if r.Code != code {
+		r.t.Errorf("status: want %d, got %d, body: %s", code, r.Code, r.short())
+	}
+	return r
+}
+
+// Assert header key is value.
+func (r *Response) Header(key, value string) *Response {
+	r.t.Helper()
+	if got := r.ResponseRecorder.Header().Get(key); got != value {
+		r.t.Errorf("header %s: want %q, got %q", key, value, got)
+	}
+	return r
+}
+
+// Assert body contains s.
+func (r *Response) Contains(s string) *Response {
+	r.t.Helper()
+	if !strings.Contains(r.Body.String(), s) {
+		r.t.Errorf("body: want containing %q, got: %s", s, r.short())
+	}
+	return r
+}
+
+// Decode json body into v.
+func (r *Response) DecodeJSON(v any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body.Bytes(), v); err != nil {
+		r.t.Errorf("decode json: %v, body: %s", err, r.short())
+	}
+	return r
+}
+
+var indexRegexp = regexp.MustCompile(`\[(\d+)\]`)
+
+// Assert value at path of json body equals want, path is like
+// "data.items.0.title", "$.data.items[0].title" also works. want is compared
+// in json, so 1 equals 1.0.
+func (r *Response) JSON(path string, want any) *Response {
+	r.t.Helper()
+	var body any
+	if err := json.Unmarshal(r.Body.Bytes(), &body); err != nil {
+		r.t.Errorf("json %s: %v, body: %s", path, err, r.short())
+		return r
+	}
+	got, ok := lookup(body, path)
+	if !ok {
+		r.t.Errorf("json %s: not found in %s", path, r.short())
+		return r
+	}
+	bs, err := json.Marshal(want)
+	if err != nil {
+		r.t.Fatalf("phoenixtest: encode json: %v", err)
+	}
+	var normalized any
+	json.Unmarshal(bs, &normalized)
+	if !reflect.DeepEqual(got, normalized) {
+		gotJSON, _ := json.Marshal(got)
+		r.t.Errorf("json %s: want %s, got %s", path, bs, gotJSON)
+	}
+	return r
+}
+
+func lookup(v any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = indexRegexp.ReplaceAllString(path, ".$1")
+	if path == "" {
+		return v, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = node[key]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// Assert some element matches css selector and contains text, text can be
+// empty. Selectors are tags, #id, .class and [attr=value] combined by
+// descendant, like "form#user input[name=email]".
+func (r *Response) HTML(selector, text string) *Response {
+	r.t.Helper()
+	nodes := r.Select(selector)
+	if len(nodes) == 0 {
+		r.t.Errorf("html %q: no element, body: %s", selector, r.short())
+		return r
+	}
+	if text == "" {
+		return r
+	}
+	var texts []string
+	for _, n := range nodes {
+		t := textOf(n)
+		if strings.Contains(t, text) {
+			return r
+		}
+		texts = append(texts, t)
+	}
+	r.t.Errorf("html %q: want text %q, got %q", selector, text, texts)
+	return r
+}
+
+// Assert count of elements match css selector, see HTML.
+func (r *Response) HTMLCount(selector string, n int) *Response {
+	r.t.Helper()
+	if got := len(r.Select(selector)); got != n {
+		r.t.Errorf("html %q: want %d elements, got %d", selector, n, got)
+	}
+	return r
+}
+
+// Elements match css selector, see HTML.
+func (r *Response) Select(selector string) []*html.Node {
+	r.t.Helper()
+	if r.doc == nil {
+		doc, err := html.Parse(strings.NewReader(r.Body.String()))
+		if err != nil {
+			r.t.Fatalf("phoenixtest: parse html: %v", err)
+		}
+		r.doc = doc
+	}
+	sel, err := parseSelector(selector)
+	if err != nil {
+		r.t.Fatalf("phoenixtest: %v", err)
+	}
+	return sel.all(r.doc)
+}
+
+// Assert it redirects to path, query of location is ignored when path has
+// none.
+func (r *Response) Redirect(path string) *Response {
+	r.t.Helper()
+	if r.Code < 300 || r.Code >= 400 {
+		r.t.Errorf("redirect: want 3xx, got %d, body: %s", r.Code, r.short())
+		return r
+	}
+	loc := r.ResponseRecorder.Header().Get("Location")
+	if u, err := url.Parse(loc); err == nil && !strings.Contains(path, "?") {
+		loc = u.Path
+	}
+	if loc != path {
+		r.t.Errorf("redirect: want %s, got %s", path, loc)
+	}
+	return r
+}
+
+// Assert flash message of kind, an empty msg asserts there is none. It needs
+// flash.Fetch in the handler.
+func (r *Response) Flash(kind, msg string) *Response {
+	r.t.Helper()
+	if got := r.assigns.Flash[kind]; got != msg {
+		r.t.Errorf("flash %s: want %q, got %q", kind, msg, got)
+	}
+	return r
+}
+
+// Follow the redirect by GET with cookies of the response, it's sent in the
+// session if there is. The request is served by the same handler, so it's for
+// routers.
+func (r *Response) Follow() *Response {
+	r.t.Helper()
+	loc := r.ResponseRecorder.Header().Get("Location")
+	if loc == "" {
+		r.t.Fatalf("phoenixtest: follow: no location, status %d", r.Code)
+	}
+	if r.session != nil {
+		return r.session.Get(loc).Send()
+	}
+	req := Get(r.t, loc)
+	for _, c := range r.Result().Cookies() {
+		if c.MaxAge >= 0 {
+			req.Cookie(c.Name, c.Value)
+		}
+	}
+	return req.Serve(r.h)
+}
+
+// Body for messages, a long body is cut.
+func (r *Response) short() string {
+	body := r.Body.String()
+	if len(body) > 512 {
+		return body[:512] + "..."
+	}
+	return body
+}
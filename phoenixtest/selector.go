@@ -0,0 +1,161 @@
+package phoenixtest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// A css selector of compounds combined by descendant or child.
+type selector []compound
+
+type compound struct {
+	child   bool // combined with the previous one by ">"
+	tag     string
+	id      string
+	classes []string
+	attrs   []attr
+}
+
+type attr struct {
+	name, value string
+	has         bool // [name] without value
+}
+
+var compoundRegexp = regexp.MustCompile(`([#.]?[\w-]+|\*)|\[\s*([\w-]+)\s*(?:=\s*("[^"]*"|'[^']*'|[^\]\s]*))?\s*\]`)
+
+func parseSelector(s string) (selector, error) {
+	var sel selector
+	child := false
+	for _, part := range strings.Fields(strings.ReplaceAll(s, ">", " > ")) {
+		if part == ">" {
+			child = true
+			continue
+		}
+		c := compound{child: child}
+		child = false
+		rest := part
+		for rest != "" {
+			loc := compoundRegexp.FindStringSubmatchIndex(rest)
+			if loc == nil || loc[0] != 0 {
+				return nil, fmt.Errorf("invalid selector %q", s)
+			}
+			m := compoundRegexp.FindStringSubmatch(rest)
+			switch token := m[1]; {
+			case token == "*":
+			case strings.HasPrefix(token, "#"):
+				c.id = token[1:]
+			case strings.HasPrefix(token, "."):
+				c.classes = append(c.classes, token[1:])
+			case token != "":
+				c.tag = strings.ToLower(token)
+			default:
+				value := strings.Trim(m[3], `"'`)
+				c.attrs = append(c.attrs, attr{name: m[2], value: value, has: loc[6] < 0})
+			}
+			rest = rest[loc[1]:]
+		}
+		sel = append(sel, c)
+	}
+	if len(sel) == 0 || sel[0].child {
+		return nil, fmt.Errorf("invalid selector %q", s)
+	}
+	return sel, nil
+}
+
+// All elements under root match the selector, in document order.
+func (sel selector) all(root *html.Node) []*html.Node {
+	var nodes []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && sel.match(n, len(sel)-1) {
+			nodes = append(nodes, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return nodes
+}
+
+// Whether n matches sel[:i+1], sel[i] is matched by n itself.
+func (sel selector) match(n *html.Node, i int) bool {
+	if !sel[i].match(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	for p := n.Parent; p != nil && p.Type == html.ElementNode; p = p.Parent {
+		if sel.match(p, i-1) {
+			return true
+		}
+		if sel[i].child {
+			return false
+		}
+	}
+	return false
+}
+
+func (c compound) match(n *html.Node) bool {
+	if c.tag != "" && n.Data != c.tag {
+		return false
+	}
+	if c.id != "" && attrOf(n, "id") != c.id {
+		return false
+	}
+	classes := strings.Fields(attrOf(n, "class"))
+	for _, want := range c.classes {
+		found := false
+		for _, class := range classes {
+			if class == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, a := range c.attrs {
+		value, ok := lookupAttr(n, a.name)
+		if !ok || !a.has && value != a.value {
+			return false
+		}
+	}
+	return true
+}
+
+func lookupAttr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func attrOf(n *html.Node, name string) string {
+	v, _ := lookupAttr(n, name)
+	return v
+}
+
+// Text in n with spaces collapsed.
+func textOf(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
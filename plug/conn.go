@@ -1,16 +1,256 @@
 package plug
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+	"net/url"
 
-// The most important abstract
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/binding"
+	"github.com/DOVECYJ/phoenix/flash"
+	"github.com/DOVECYJ/phoenix/render"
+	"github.com/a-h/templ"
+	"github.com/go-chi/chi/v5"
+)
+
+const phoenixConn _key = "phoenix.conn"
+
+type _key string
+
+// The most important abstract, it holds the request and response of a
+// connection through plugs.
+//
+// Response helpers like JSON and HTML send with the status put by PutStatus,
+// a plug which sends a response or calls Halt stops the following plugs.
 type Conn struct {
-	w   http.ResponseWriter
-	r   *http.Request
-	err error
+	w          http.ResponseWriter // current writer, may be wrapped by middlewares
+	r          *http.Request
+	resp       *response
+	status     int
+	halted     bool
+	beforeSend []func(*Conn)
 }
 
-func (c *Conn) Terminate() {
+// Create a conn of w and r. The request of conn carries it and assigns in its
+// context.
+func NewConn(w http.ResponseWriter, r *http.Request) *Conn {
+	ctx, _ := phoenix.EnsureAssigns(r.Context())
+	c := &Conn{status: http.StatusOK}
+	c.resp = &response{ResponseWriter: w, conn: c}
+	c.w = c.resp
+	c.r = r.WithContext(context.WithValue(ctx, phoenixConn, c))
+	return c
+}
 
+// Conn of the request, it's created when the request has none. The conn is
+// updated to w and r, since middlewares may wrap them.
+func ConnOf(w http.ResponseWriter, r *http.Request) *Conn {
+	c, ok := r.Context().Value(phoenixConn).(*Conn)
+	if !ok {
+		return NewConn(w, r)
+	}
+	c.w, c.r = w, r
+	return c
 }
 
-func (c *Conn) Render() {}
+func (c *Conn) Request() *http.Request {
+	return c.r
+}
+
+// Writer of the response, before send callbacks run when it's written.
+func (c *Conn) Writer() http.ResponseWriter {
+	return c.w
+}
+
+func (c *Conn) Context() context.Context {
+	return c.r.Context()
+}
+
+// Replace context of the request, it should be derived from Context.
+func (c *Conn) SetContext(ctx context.Context) *Conn {
+	c.r = c.r.WithContext(ctx)
+	return c
+}
+
+// Header of the request.
+func (c *Conn) Header(key string) string {
+	return c.r.Header.Get(key)
+}
+
+// Assigns of the request, it's shared with templ components and the
+// middlewares of router package.
+func (c *Conn) Assigns() *phoenix.Assigns {
+	return phoenix.GetAssigns(c.r.Context())
+}
+
+// Assign a value to key.
+func (c *Conn) Assign(key string, val any) *Conn {
+	c.Assigns().Set(key, val)
+	return c
+}
+
+// Get a value assigned to key.
+func (c *Conn) Assigned(key string) (any, bool) {
+	return c.Assigns().Get(key)
+}
+
+// Params of path, body form and query, in that order of precedence. A
+// multipart body is not parsed, so files can be streamed by upload.Receive,
+// its values are read by Request().ParseMultipartForm. It returns error when
+// the body is not a valid form.
+func (c *Conn) Params() (url.Values, error) {
+	if err := c.r.ParseForm(); err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	for k, v := range c.r.Form {
+		params[k] = v
+	}
+	if rctx := chi.RouteContext(c.r.Context()); rctx != nil {
+		for i, k := range rctx.URLParams.Keys {
+			if k != "*" {
+				params.Set(k, rctx.URLParams.Values[i])
+			}
+		}
+	}
+	return params, nil
+}
+
+// Param of name in path or query, the body is not read, see Params for body
+// form values.
+func (c *Conn) Param(name string) string {
+	if v := chi.URLParam(c.r, name); v != "" {
+		return v
+	}
+	return c.r.URL.Query().Get(name)
+}
+
+// Bind params of the request into obj, see binding.BindAll.
+func (c *Conn) Bind(obj any) error {
+	return binding.BindAll(c.r, obj)
+}
+
+// Put status of the response, it's used by the response helpers.
+func (c *Conn) PutStatus(code int) *Conn {
+	c.status = code
+	return c
+}
+
+// Status of the response, it's the sent one after sent.
+func (c *Conn) Status() int {
+	return c.status
+}
+
+// Set a header of the response.
+func (c *Conn) PutRespHeader(key, value string) *Conn {
+	c.w.Header().Set(key, value)
+	return c
+}
+
+// Put a flash message of kind, see flash.Put.
+func (c *Conn) PutFlash(kind, msg string) *Conn {
+	flash.Put(c.w, c.r, kind, msg)
+	return c
+}
+
+// Stop the following plugs, the response is sent by the endpoint if it's not.
+func (c *Conn) Halt() {
+	c.halted = true
+}
+
+func (c *Conn) Halted() bool {
+	return c.halted
+}
+
+// Whether the response header is written.
+func (c *Conn) Sent() bool {
+	return c.resp.sent
+}
+
+// Register fn to run right before the response header is written, so it can
+// still change the header, and the status by PutStatus. Callbacks run in
+// reverse order of registering.
+func (c *Conn) RegisterBeforeSend(fn func(*Conn)) {
+	c.beforeSend = append(c.beforeSend, fn)
+}
+
+// Send text.
+func (c *Conn) Text(s string, opts ...render.Option) {
+	render.String(c.w, s, c.options(opts)...)
+}
+
+// Send data in json.
+func (c *Conn) JSON(data any, opts ...render.Option) {
+	render.JSON(c.w, data, c.options(opts)...)
+}
+
+// Send HTML component, it can read assigns of the conn.
+func (c *Conn) HTML(component templ.Component, opts ...render.Option) {
+	render.HTML(c.w, c.r, component, c.options(opts)...)
+}
+
+// Send data by its type, see render.Render.
+func (c *Conn) Render(data any, opts ...render.Option) {
+	render.Render(c.w, data, c.options(opts)...)
+}
+
+// Send error as problem, or an error page when the client accepts html. The
+// conn is halted.
+func (c *Conn) Error(err error, opts ...render.Option) {
+	render.Error(c.w, c.r, err, opts...)
+	c.Halt()
+}
+
+// Redirect to url, the status is 302 unless a 3xx one is put. The conn is
+// halted.
+func (c *Conn) Redirect(url string) {
+	code := http.StatusFound
+	if c.status >= 300 && c.status < 400 {
+		code = c.status
+	}
+	http.Redirect(c.w, c.r, url, code)
+	c.Halt()
+}
+
+// Send status without body.
+func (c *Conn) SendStatus(code int) {
+	c.status = code
+	c.w.WriteHeader(code)
+}
+
+func (c *Conn) options(opts []render.Option) []render.Option {
+	return append([]render.Option{render.Status(c.status)}, opts...)
+}
+
+// response runs before send callbacks of conn.
+type response struct {
+	http.ResponseWriter
+	conn *Conn
+	sent bool
+}
+
+func (w *response) WriteHeader(code int) {
+	if w.sent {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.sent = true
+	w.conn.status = code
+	for i := len(w.conn.beforeSend) - 1; i >= 0; i-- {
+		w.conn.beforeSend[i](w.conn)
+	}
+	// callbacks may put another status
+	w.ResponseWriter.WriteHeader(w.conn.status)
+}
+
+func (w *response) Write(bs []byte) (int, error) {
+	if !w.sent {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(bs)
+}
+
+// Unwrap is used by http.ResponseController.
+func (w *response) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
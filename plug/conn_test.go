@@ -0,0 +1,152 @@
+package plug
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestEndpointHalt(t *testing.T) {
+	var ran []string
+	step := func(name string, halt bool) PlugFunc {
+		return func(c *Conn) {
+			ran = append(ran, name)
+			if halt {
+				c.Halt()
+			}
+		}
+	}
+	w := httptest.NewRecorder()
+	NewEndpoint(step("a", false), step("b", true), step("c", false)).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if strings.Join(ran, ",") != "a,b" {
+		t.Errorf("ran %v, want a,b", ran)
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("code = %d", w.Code)
+	}
+
+	// a sent response stops the following plugs too
+	ran = nil
+	send := PlugFunc(func(c *Conn) {
+		ran = append(ran, "send")
+		c.Text("hi")
+	})
+	NewEndpoint(send, step("c", false)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if strings.Join(ran, ",") != "send" {
+		t.Errorf("ran %v, want send", ran)
+	}
+}
+
+func TestPipeThroughHalt(t *testing.T) {
+	auth := PlugFunc(func(c *Conn) {
+		if c.Header("Authorization") == "" {
+			// halted without a response, the endpoint sends the status
+			c.PutStatus(http.StatusUnauthorized).Halt()
+			return
+		}
+		c.Assign("user", "bob")
+	})
+	show := PlugFunc(func(c *Conn) {
+		user, _ := c.Assigned("user")
+		c.Text(user.(string))
+	})
+	r := NewEndpoint(NewRouter(PipeThrough(auth), Get("/", show)))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusUnauthorized || w.Body.Len() != 0 {
+		t.Errorf("halted: %d %q", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "t")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "bob\n" {
+		t.Errorf("passed: %d %q", w.Code, w.Body)
+	}
+}
+
+func TestBeforeSend(t *testing.T) {
+	var order []string
+	register := PlugFunc(func(c *Conn) {
+		c.RegisterBeforeSend(func(c *Conn) {
+			order = append(order, "first")
+			c.PutRespHeader("X-First", "1")
+		})
+		c.RegisterBeforeSend(func(c *Conn) {
+			order = append(order, "second")
+			if c.Status() == http.StatusOK {
+				c.PutStatus(http.StatusAccepted)
+			}
+		})
+	})
+
+	// the implicit status of ServeHTTP goes through the callbacks
+	w := httptest.NewRecorder()
+	NewEndpoint(register).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if strings.Join(order, ",") != "second,first" {
+		t.Errorf("order = %v, want second,first", order)
+	}
+	if w.Code != http.StatusAccepted || w.Header().Get("X-First") != "1" {
+		t.Errorf("implicit: %d %v", w.Code, w.Header())
+	}
+
+	order = nil
+	w = httptest.NewRecorder()
+	NewEndpoint(register, PlugFunc(func(c *Conn) { c.JSON(1) })).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if len(order) != 2 || w.Code != http.StatusAccepted {
+		t.Errorf("sent: %v %d", order, w.Code)
+	}
+}
+
+func TestParams(t *testing.T) {
+	r := httptest.NewRequest("POST", "/users/7?id=8&q=go&name=query", strings.NewReader("name=form&age=3"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "7")
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	c := NewConn(httptest.NewRecorder(), r)
+
+	params, err := c.Params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, want := range map[string]string{"id": "7", "name": "form", "age": "3", "q": "go"} {
+		if got := params.Get(k); got != want {
+			t.Errorf("params %s = %q, want %q", k, got, want)
+		}
+	}
+	if c.Param("id") != "7" || c.Param("q") != "go" || c.Param("age") != "" {
+		t.Errorf("param: id=%q q=%q age=%q", c.Param("id"), c.Param("q"), c.Param("age"))
+	}
+
+	bad := httptest.NewRequest("POST", "/", strings.NewReader("a=%zz"))
+	bad.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if _, err := NewConn(httptest.NewRecorder(), bad).Params(); err == nil {
+		t.Error("invalid form should fail")
+	}
+}
+
+func TestRedirect(t *testing.T) {
+	cases := []struct {
+		status, want int
+	}{
+		{http.StatusOK, http.StatusFound},
+		{http.StatusSeeOther, http.StatusSeeOther},
+		{http.StatusMovedPermanently, http.StatusMovedPermanently},
+		{http.StatusBadRequest, http.StatusFound},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		c := NewConn(w, httptest.NewRequest("GET", "/", nil))
+		c.PutStatus(tc.status).Redirect("/users")
+		if w.Code != tc.want || w.Header().Get("Location") != "/users" || !c.Halted() {
+			t.Errorf("status %d: redirect %d %q, want %d", tc.status, w.Code, w.Header().Get("Location"), tc.want)
+		}
+	}
+}
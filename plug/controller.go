@@ -1,5 +1,11 @@
 package plug
 
+import (
+	"net/http"
+
+	"github.com/DOVECYJ/phoenix/router"
+)
+
 type Controller interface {
 	Index(*Conn)  // index: show a list of object
 	Edit(*Conn)   // edit: show edit form
@@ -9,3 +15,65 @@ type Controller interface {
 	Update(*Conn) // update: save update object
 	Delete(*Conn) // delete: delete a object by id
 }
+
+// Controller c as router.IResource, so it can be routed by router.Resource on
+// chi. The conn of actions shares context with chi middlewares, ids fetched
+// by middleware.FetchID, assigns and flash are the same.
+//
+//	r.Route("/users", router.Resource(plug.AsResource(controllers.UserController{})))
+func AsResource(c Controller) router.IResource {
+	if c == nil {
+		panic("controller can not be nil")
+	}
+	if s, ok := c.(controller); ok {
+		return s.rsc
+	}
+	return resource{c}
+}
+
+// resource routes Controller as router.IResource.
+type resource struct {
+	c Controller
+}
+
+func (s resource) Index(w http.ResponseWriter, r *http.Request)  { s.c.Index(ConnOf(w, r)) }
+func (s resource) Edit(w http.ResponseWriter, r *http.Request)   { s.c.Edit(ConnOf(w, r)) }
+func (s resource) New(w http.ResponseWriter, r *http.Request)    { s.c.New(ConnOf(w, r)) }
+func (s resource) Show(w http.ResponseWriter, r *http.Request)   { s.c.Show(ConnOf(w, r)) }
+func (s resource) Create(w http.ResponseWriter, r *http.Request) { s.c.Create(ConnOf(w, r)) }
+func (s resource) Update(w http.ResponseWriter, r *http.Request) { s.c.Update(ConnOf(w, r)) }
+func (s resource) Delete(w http.ResponseWriter, r *http.Request) { s.c.Delete(ConnOf(w, r)) }
+
+// Controller is used by router for names.
+func (s resource) Controller() any { return s.c }
+
+// router.IResource rsc as Controller, so it can be routed by Resource in a
+// plug router. Actions of rsc get the writer and request of the conn, ids,
+// assigns and flash are the same.
+//
+//	plug.Resource("/users", plug.ResourceController(controllers.UserController{}))
+func ResourceController(rsc router.IResource) Controller {
+	if rsc == nil {
+		panic("resource can not be nil")
+	}
+	if s, ok := rsc.(resource); ok {
+		return s.c
+	}
+	return controller{rsc}
+}
+
+// controller routes router.IResource as Controller.
+type controller struct {
+	rsc router.IResource
+}
+
+func (s controller) Index(c *Conn)  { s.rsc.Index(c.w, c.r) }
+func (s controller) Edit(c *Conn)   { s.rsc.Edit(c.w, c.r) }
+func (s controller) New(c *Conn)    { s.rsc.New(c.w, c.r) }
+func (s controller) Show(c *Conn)   { s.rsc.Show(c.w, c.r) }
+func (s controller) Create(c *Conn) { s.rsc.Create(c.w, c.r) }
+func (s controller) Update(c *Conn) { s.rsc.Update(c.w, c.r) }
+func (s controller) Delete(c *Conn) { s.rsc.Delete(c.w, c.r) }
+
+// Controller is used by router for names.
+func (s controller) Controller() any { return s.rsc }
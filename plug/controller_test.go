@@ -0,0 +1,109 @@
+package plug
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/flash"
+	"github.com/DOVECYJ/phoenix/router"
+	"github.com/go-chi/chi/v5"
+)
+
+// NoteController is a Controller of plug.
+type NoteController struct{ PostController }
+
+func (NoteController) Show(c *Conn) {
+	c.Text(describe(c.Request()))
+}
+
+func (NoteController) Create(c *Conn) {
+	c.PutFlash(flash.Info, "created")
+	c.Redirect("/notes/1")
+}
+
+// ItemController is a router.IResource.
+type ItemController struct{ router.IResource }
+
+func (ItemController) Show(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, describe(r))
+}
+
+func (ItemController) Create(w http.ResponseWriter, r *http.Request) {
+	flash.Put(w, r, flash.Info, "created")
+	http.Redirect(w, r, "/items/1", http.StatusFound)
+}
+
+// Id, assign and flash seen by an action.
+func describe(r *http.Request) string {
+	user, _ := phoenix.GetAssigns(r.Context()).Get("user")
+	return fmt.Sprintf("id=%d user=%v flash=%s", phoenix.IntID.MustGet(r.Context()), user, phoenix.Flash(r.Context())[flash.Info])
+}
+
+var assignUser = PlugFunc(func(c *Conn) {
+	c.Assign("user", "bob")
+})
+
+func TestControllerRoundTrip(t *testing.T) {
+	flash.SetSecret([]byte("test secret"))
+
+	// plug.Controller routed by router.Resource on chi
+	chiRouter := chi.NewRouter()
+	chiRouter.Use(flash.Fetch)
+	PipeThrough(assignUser)(chiRouter)
+	chiRouter.Route("/notes", router.Resource(AsResource(NoteController{})))
+
+	// router.IResource routed by plug.Resource
+	plugRouter := flash.Fetch(NewEndpoint(NewRouter(
+		PipeThrough(assignUser),
+		Resource("/items", ResourceController(ItemController{})),
+	)))
+
+	for path, h := range map[string]http.Handler{"/notes": chiRouter, "/items": plugRouter} {
+		w := serve(h, http.MethodPost, path)
+		if w.Code != http.StatusFound || w.Header().Get("Location") != path+"/1" {
+			t.Fatalf("%s: create %d %v", path, w.Code, w.Header())
+		}
+		r := httptest.NewRequest(http.MethodGet, w.Header().Get("Location"), nil)
+		for _, c := range w.Result().Cookies() {
+			r.AddCookie(c)
+		}
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if got, want := w.Body.String(), "id=1 user=bob flash=created\n"; got != want {
+			t.Errorf("%s: show %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestControllerUnwrap(t *testing.T) {
+	if _, ok := AsResource(ResourceController(ItemController{})).(ItemController); !ok {
+		t.Error("AsResource does not unwrap ResourceController")
+	}
+	if _, ok := ResourceController(AsResource(NoteController{})).(NoteController); !ok {
+		t.Error("ResourceController does not unwrap AsResource")
+	}
+
+	chiRouter := chi.NewRouter()
+	chiRouter.Route("/items", router.Resource(AsResource(ResourceController(ItemController{}))))
+	chiRouter.Route("/notes", router.Resource(AsResource(NoteController{})))
+	plugRouter := NewRouter(
+		Resource("/notes", ResourceController(AsResource(NoteController{}))),
+		Resource("/items", ResourceController(ItemController{})),
+	)
+	for _, mux := range []chi.Routes{chiRouter, plugRouter.Mux()} {
+		names := map[string]string{}
+		for _, route := range router.Routes(mux) {
+			if route.Method == http.MethodGet {
+				names[route.Pattern] = route.Name
+			}
+		}
+		for pattern, name := range map[string]string{"/items/{id}": "item_path", "/notes/{id}": "note_path", "/notes": "notes_path"} {
+			if names[pattern] != name {
+				t.Errorf("%s is named %q, want %q", pattern, names[pattern], name)
+			}
+		}
+	}
+}
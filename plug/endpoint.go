@@ -14,21 +14,34 @@ func Plug(p ...plug) {
 	defaultEndpoint.Plug(p...)
 }
 
-// endpoint 是一个http.Handler,有一个Pluger列表
+// Endpoint is the root of server, plugs are run in order until one sends a
+// response or halts the conn. An endpoint is a plug too, so it can be nested.
 type Endpoint struct {
 	plugs []plug
 }
 
+func NewEndpoint(p ...plug) *Endpoint {
+	return &Endpoint{plugs: p}
+}
+
 func (e *Endpoint) Plug(p ...plug) {
 	e.plugs = append(e.plugs, p...)
 }
 
-func (e *Endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	conn := &Conn{w: w, r: r}
+func (e *Endpoint) Handle(c *Conn) {
 	for i := range e.plugs {
-		e.plugs[i].Handle(conn)
-		if conn.err != nil {
+		if c.halted || c.Sent() {
 			return
 		}
+		e.plugs[i].Handle(c)
+	}
+}
+
+func (e *Endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn := ConnOf(w, r)
+	e.Handle(conn)
+	if !conn.Sent() {
+		// nothing is sent, the status still needs before send callbacks
+		conn.resp.WriteHeader(conn.status)
 	}
 }
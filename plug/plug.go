@@ -1,6 +1,33 @@
-// Plug is the the new router system. It is not complete yet, so do not use it.
+// Package plug is a router system in the way of elixir plug. A plug takes a
+// *Conn, it reads the request, assigns values, and sends a response or halts
+// the conn. Plugs are composed into pipelines and routers, an endpoint runs
+// them in order.
+//
+// Usage:
+//
+//	router := plug.NewRouter(
+//		plug.PipeThrough(plug.PlugFunc(fetchUser)),
+//		plug.Get("/", plug.PlugFunc(controllers.Index)),
+//		plug.Resource("/users", controllers.UserController{},
+//			plug.Resource("/posts", controllers.PostController{}),
+//		),
+//	)
+//	endpoint := plug.NewEndpoint(plug.PlugFunc(requestID), router)
+//	http.ListenAndServe(":8080", endpoint)
+//
+// It's built on chi, so handlers and middlewares of chi and the router
+// package work together with plugs. Controllers of both can be mixed during
+// migration, a router.IResource is routed in a plug router by
+// ResourceController, and a Controller is routed on chi by AsResource.
 package plug
 
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
 type plug interface {
 	Handle(*Conn)
 }
@@ -8,3 +35,14 @@ type plug interface {
 type PlugFunc func(*Conn)
 
 func (p PlugFunc) Handle(c *Conn) { p(c) }
+
+// Name of plug p, like "controllers.Index".
+func nameOf(p plug) string {
+	if fn, ok := p.(PlugFunc); ok {
+		if f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()); f != nil {
+			name := f.Name()
+			return name[strings.LastIndexByte(name, '/')+1:]
+		}
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", p), "*")
+}
@@ -1,38 +1,62 @@
 package plug
 
 import (
-	"context"
+	"fmt"
 	"net/http"
 
+	"github.com/DOVECYJ/phoenix/router"
 	"github.com/go-chi/chi/v5"
 )
 
-const phoenixConn _key = "phoenix.conn"
+type routeFunc func(chi.Router)
 
-type (
-	_key      string
-	routeFunc func(chi.Router)
-)
-
-// router   是一个分发器，本身也是一个Pluger，考虑分发如何实现
+// Router dispatches conn by method and path, it's a plug and http.Handler.
+// Routes are set up by route funcs like Get, Scope and Resource.
 type Router struct {
 	mux *chi.Mux
 }
 
+func NewRouter(p ...routeFunc) *Router {
+	r := &Router{}
+	r.Route(p...)
+	return r
+}
+
 func (r *Router) Handle(c *Conn) {
 	// dispatch router
-	ctx := context.WithValue(c.r.Context(), phoenixConn, c)
-	r.mux.ServeHTTP(c.w, c.r.WithContext(ctx))
+	r.Mux().ServeHTTP(c.w, c.r)
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Handle(ConnOf(w, req))
+}
+
+// The chi router, it can be used by router.Routes, router.Load and chi
+// handlers.
+func (r *Router) Mux() *chi.Mux {
+	if r.mux == nil {
+		r.mux = chi.NewRouter()
+	}
+	return r.mux
+}
+
+// Set up routes at the root.
+func (r *Router) Route(p ...routeFunc) {
+	for i := range p {
+		p[i](r.Mux())
+	}
 }
 
 func (r *Router) Scope(prefix string, p ...routeFunc) {
-	r.mux.Route(prefix, func(r chi.Router) {
+	r.Mux().Route(prefix, func(r chi.Router) {
 		for i := range p {
 			p[i](r)
 		}
 	})
 }
 
+// Run plugs before routes in the scope. Middlewares of chi can be used by
+// Use.
 func PipeThrough(p ...plug) routeFunc {
 	mids := make([]func(http.Handler) http.Handler, len(p))
 	for i := range p {
@@ -43,6 +67,30 @@ func PipeThrough(p ...plug) routeFunc {
 	}
 }
 
+// Use middlewares of chi in the scope.
+func Use(mws ...func(http.Handler) http.Handler) routeFunc {
+	return func(r chi.Router) {
+		r.Use(mws...)
+	}
+}
+
+// Define pipeline name of plugs, see router.Pipeline.
+func Pipeline(name string, p ...plug) {
+	mids := make([]func(http.Handler) http.Handler, len(p))
+	for i := range p {
+		mids[i] = wrapMiddleware(p[i])
+	}
+	router.Pipeline(name, mids...)
+}
+
+// Use pipelines by name in the scope, see router.PipeThrough. They can be
+// defined by Pipeline or router.Pipeline.
+func Pipelines(names ...string) routeFunc {
+	return func(r chi.Router) {
+		router.PipeThrough(r, names...)
+	}
+}
+
 func Scope(path string, p ...routeFunc) routeFunc {
 	return func(r chi.Router) {
 		r.Route(path, func(r chi.Router) {
@@ -53,53 +101,130 @@ func Scope(path string, p ...routeFunc) routeFunc {
 	}
 }
 
-func Get(path string, p plug) routeFunc {
+// Scope without path, plugs piped through in it don't affect others.
+func Group(p ...routeFunc) routeFunc {
+	return func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			for i := range p {
+				p[i](r)
+			}
+		})
+	}
+}
+
+func Method(method, path string, p plug) routeFunc {
 	return func(r chi.Router) {
-		r.Get(path, wrap(p))
+		r.Method(method, path, handler{p})
 	}
 }
 
+func Get(path string, p plug) routeFunc {
+	return Method(http.MethodGet, path, p)
+}
+
 func Post(path string, p plug) routeFunc {
-	return nil
+	return Method(http.MethodPost, path, p)
 }
 
-func Resource(path string, c Controller, p ...routeFunc) routeFunc {
-	return nil
+func Put(path string, p plug) routeFunc {
+	return Method(http.MethodPut, path, p)
 }
 
-func wrap(p plug) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		conn := r.Context().Value(phoenixConn).(*Conn)
-		p.Handle(conn)
-	}
+func Patch(path string, p plug) routeFunc {
+	return Method(http.MethodPatch, path, p)
 }
 
-func wrapFunc(fn PlugFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		conn := r.Context().Value(phoenixConn).(*Conn)
-		fn(conn)
+func Delete(path string, p plug) routeFunc {
+	return Method(http.MethodDelete, path, p)
+}
+
+func Head(path string, p plug) routeFunc {
+	return Method(http.MethodHead, path, p)
+}
+
+func Options(path string, p plug) routeFunc {
+	return Method(http.MethodOptions, path, p)
+}
+
+// Route a full RESTful actions of c at path, routes are named as
+// router.Resource. opts are router.ResourceOption like router.WithID and
+// router.Only, but not router.Shallow. Route funcs are nested under the
+// member, like /users/{user_id}/posts, the parent id is fetched into context:
+//
+//	plug.Resource("/users", controllers.UserController{},
+//		router.WithID(middleware.UUID),
+//		plug.Resource("/posts", controllers.PostController{}),
+//		plug.Get("/profile", plug.PlugFunc(controllers.Profile)),
+//	)
+//
+//	userID := phoenix.NewKey[string]("user_id").MustGet(conn.Context())
+//
+// Nested resources are named with the parent, like user_posts_path. It
+// panics when an opt is neither.
+func Resource(path string, c Controller, opts ...any) routeFunc {
+	if c == nil {
+		panic("controller can not be nil")
+	}
+	var options []router.ResourceOption
+	var nested []routeFunc
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case router.ResourceOption:
+			options = append(options, opt)
+		case routeFunc:
+			nested = append(nested, opt)
+		default:
+			panic(fmt.Sprintf("invalid resource option %T", opt))
+		}
 	}
+	if len(nested) > 0 {
+		options = append(options, router.NestedRoutes(func(r chi.Router, scope string) {
+			for i := range nested {
+				nested[i](scoped{r, scope})
+			}
+		}))
+	}
+	return func(r chi.Router) {
+		name := router.ResourceName(c)
+		param := name + "_id"
+		if s, ok := r.(scoped); ok {
+			name = s.name + "_" + name
+		}
+		sub := chi.NewRouter()
+		router.Resource(resource{c}, append([]router.ResourceOption{router.Name(name), router.Param(param)}, options...)...)(sub)
+		r.Mount(path, sub)
+	}
+}
+
+// Router in a resource member, nested resources are named with it.
+type scoped struct {
+	chi.Router
+	name string
+}
+
+// handler runs plug p on conn of the request.
+type handler struct {
+	p plug
+}
+
+func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.p.Handle(ConnOf(w, r))
+}
+
+// Name of the plug, shown by router.Routes.
+func (h handler) String() string {
+	return nameOf(h.p)
 }
 
 func wrapMiddleware(p plug) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			conn := r.Context().Value(phoenixConn).(*Conn)
+			conn := ConnOf(w, r)
 			p.Handle(conn)
-			next.ServeHTTP(w, r)
+			if conn.halted || conn.Sent() {
+				return
+			}
+			next.ServeHTTP(conn.w, conn.r)
 		})
 	}
 }
-
-func test() {
-	var r Router
-	r.Scope("/user",
-		PipeThrough(PlugFunc(func(c *Conn) {})),
-		Get("/", PlugFunc(func(c *Conn) {})),
-		Scope("/post",
-			Resource("/", struct{ Controller }{},
-				Resource("/post", struct{ Controller }{}),
-			),
-		),
-	)
-}
@@ -0,0 +1,116 @@
+package plug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/middleware"
+	"github.com/DOVECYJ/phoenix/router"
+)
+
+type PostController struct{}
+
+func (PostController) Index(c *Conn) { c.Text("index") }
+func (PostController) Edit(c *Conn)  { c.Text("edit") }
+func (PostController) New(c *Conn)   { c.Text("new") }
+func (PostController) Show(c *Conn) {
+	c.Text(phoenix.NewKey[string]("id").MustGet(c.Context()))
+}
+func (PostController) Create(c *Conn) { c.SendStatus(http.StatusCreated) }
+func (PostController) Update(c *Conn) { c.Text("update") }
+func (PostController) Delete(c *Conn) { c.SendStatus(http.StatusNoContent) }
+
+type CommentController struct{ PostController }
+
+func (CommentController) Index(c *Conn) {
+	c.Text(phoenix.NewKey[string]("post_id").MustGet(c.Context()))
+}
+
+func serve(h http.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestRouterScope(t *testing.T) {
+	mark := func(v string) PlugFunc {
+		return func(c *Conn) { c.PutRespHeader("X-Pipe", v) }
+	}
+	text := func(s string) PlugFunc {
+		return func(c *Conn) { c.Text(s) }
+	}
+	r := NewRouter(
+		Get("/", text("home")),
+		Scope("/admin",
+			PipeThrough(mark("admin")),
+			Get("/", text("admin")),
+		),
+		Group(
+			PipeThrough(mark("group")),
+			Get("/about", text("about")),
+		),
+		Get("/faq", text("faq")),
+	)
+	cases := []struct{ path, body, pipe string }{
+		{"/", "home\n", ""},
+		{"/admin", "admin\n", "admin"},
+		{"/about", "about\n", "group"},
+		{"/faq", "faq\n", ""},
+	}
+	for _, tc := range cases {
+		w := serve(r, "GET", tc.path)
+		if w.Body.String() != tc.body || w.Header().Get("X-Pipe") != tc.pipe {
+			t.Errorf("%s: %q pipe %q, want %q pipe %q", tc.path, w.Body, w.Header().Get("X-Pipe"), tc.body, tc.pipe)
+		}
+	}
+}
+
+func TestResource(t *testing.T) {
+	r := NewRouter(Resource("/posts", PostController{},
+		router.WithID(middleware.Slug),
+		router.Except("delete"),
+		Resource("/comments", CommentController{}, router.Only("index")),
+	))
+	names := map[string]string{}
+	for _, route := range router.Routes(r.Mux()) {
+		names[route.Method+" "+route.Pattern] = route.Name
+	}
+	want := map[string]string{
+		"GET /posts":                    "posts_path",
+		"GET /posts/{id}":               "post_path",
+		"GET /posts/{id}/edit":          "post_edit_path",
+		"GET /posts/{post_id}/comments": "post_comments_path",
+	}
+	for route, name := range want {
+		if names[route] != name {
+			t.Errorf("%s is named %q, want %q", route, names[route], name)
+		}
+	}
+	if _, ok := names["DELETE /posts/{id}"]; ok {
+		t.Error("delete is routed")
+	}
+	if _, ok := names["GET /posts/{post_id}/comments/{id}"]; ok {
+		t.Error("show of comments is routed")
+	}
+
+	if w := serve(r, "GET", "/posts/hello-world"); w.Body.String() != "hello-world\n" {
+		t.Errorf("show: %q", w.Body)
+	}
+	if w := serve(r, "GET", "/posts/hello/comments"); w.Body.String() != "hello\n" {
+		t.Errorf("nested: %q", w.Body)
+	}
+	if w := serve(r, "POST", "/posts"); w.Code != http.StatusCreated {
+		t.Errorf("create: %d", w.Code)
+	}
+}
+
+func TestResourceInvalidOption(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("invalid option should panic")
+		}
+	}()
+	Resource("/posts", PostController{}, "posts")
+}
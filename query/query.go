@@ -0,0 +1,404 @@
+// Package query parse filtering, sorting, field selection and paging from
+// query string into rel queries, only the columns in a Schema are allowed.
+//
+// Filters use column[op]=value, a column without op means eq:
+//
+//	GET /users?name=foo                     name = 'foo'
+//	GET /users?age[gte]=18&age[lt]=60       age >= 18 AND age < 60
+//	GET /users?status[in]=active,banned     status IN ('active', 'banned')
+//	GET /users?name[like]=jo                name LIKE '%jo%'
+//	GET /users?deleted_at[null]=true        deleted_at IS NULL
+//	GET /users?created_at[between]=2024-01-01,2024-12-31
+//
+// Supported ops are eq, ne, lt, lte, gt, gte, in, nin, like, null and between.
+// like matches a substring, % and _ in the value are escaped by backslash, the
+// default escape character of LIKE in MySQL and PostgreSQL. SQLite has no
+// default one, so a value with them matches nothing there.
+// A bound of between can be empty, like age[between]=18, for an open range.
+// Values of in, nin and between are separated by comma.
+//
+// Sorting and field selection use sort and fields, '-' means descending:
+//
+//	GET /users?sort=-created_at,name&fields=id,name
+//
+// Paging params page, page_size, cursor and limit are read by package
+// paginate.
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/paginate"
+	"github.com/go-rel/rel"
+)
+
+// Kind of column values, query string values are converted by it.
+type Kind int
+
+const (
+	String Kind = iota
+	Int
+	Float
+	Bool
+	Time // RFC 3339 or date like 2006-01-02
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Int:
+		return "integer"
+	case Float:
+		return "number"
+	case Bool:
+		return "boolean"
+	case Time:
+		return "time"
+	}
+	return "string"
+}
+
+// Filter operator.
+type Op string
+
+const (
+	Eq      Op = "eq"
+	Ne      Op = "ne"
+	Lt      Op = "lt"
+	Lte     Op = "lte"
+	Gt      Op = "gt"
+	Gte     Op = "gte"
+	In      Op = "in"
+	Nin     Op = "nin"
+	Like    Op = "like"
+	Null    Op = "null"
+	Between Op = "between"
+)
+
+var ops = []Op{Eq, Ne, Lt, Lte, Gt, Gte, In, Nin, Like, Null, Between}
+
+// Keys of query string which are not filters.
+var reserved = []string{"sort", "fields", "page", "page_size", "cursor", "limit"}
+
+// Schema is the whitelist of a resource.
+//
+// Usage:
+//
+//	var UserSchema = query.Schema{
+//		Filters: map[string]query.Kind{"name": query.String, "age": query.Int},
+//		Sorts:   []string{"id", "age", "created_at"},
+//		Sort:    "-id",
+//	}
+type Schema struct {
+	Filters map[string]Kind // filterable columns and kind of their values
+	Sorts   []string        // sortable columns
+	Fields  []string        // selectable columns, nil means fields is not allowed
+	Sort    string          // default sorting, like "-created_at,id"
+}
+
+// A condition of filter, Values are converted by the Kind of column.
+type Filter struct {
+	Field  string
+	Op     Op
+	Values []any
+}
+
+// Sorting of a column.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Query parsed from query string. Filters, sorting and field selection are
+// applied by Queriers, and paging is done by Find.
+type Query struct {
+	paginate.Params
+	Filters []Filter
+	Sorts   []Sort
+	Fields  []string
+}
+
+// Parse query from query string of r, see Parse.
+func FromRequest(r *http.Request, s Schema) (Query, error) {
+	return Parse(r.URL.Query(), s)
+}
+
+// Parse query from values and check them by s. Errors are returned as
+// phoenix.FieldError keyed by query string key, like "age[gte]". A plain key
+// which is not in the schema is ignored, so other params can share the query
+// string, but a key with op must be filterable.
+func Parse(values url.Values, s Schema) (Query, error) {
+	var q Query
+	ferr := phoenix.FieldError{}
+	p, err := paginate.FromQuery(values)
+	if err != nil {
+		var perr phoenix.FieldError
+		if !errors.As(err, &perr) {
+			return q, err
+		}
+		for k, v := range perr {
+			ferr[k] = v
+		}
+	}
+	q.Params = p
+
+	for key, vs := range values {
+		if slices.Contains(reserved, key) || len(vs) == 0 {
+			continue
+		}
+		field, op, hasOp := strings.Cut(key, "[")
+		kind, ok := s.Filters[field]
+		if !ok {
+			if hasOp {
+				ferr[key] = invalid("%s is not filterable", field)
+			}
+			continue
+		}
+		if hasOp {
+			op, ok = strings.CutSuffix(op, "]")
+			if !ok || !slices.Contains(ops, Op(op)) {
+				ferr[key] = oneOf(ops)
+				continue
+			}
+		} else {
+			op = string(Eq)
+		}
+		for _, v := range vs {
+			f, err := parseFilter(field, Op(op), kind, v)
+			if err != nil {
+				ferr[key] = err
+				break
+			}
+			q.Filters = append(q.Filters, f)
+		}
+	}
+
+	sort := s.Sort
+	if values.Has("sort") {
+		sort = values.Get("sort")
+	}
+	for _, v := range splitList(sort) {
+		field, desc := strings.CutPrefix(v, "-")
+		if !slices.Contains(s.Sorts, field) {
+			ferr["sort"] = oneOf(s.Sorts)
+			break
+		}
+		q.Sorts = append(q.Sorts, Sort{Field: field, Desc: desc})
+	}
+
+	if values.Has("fields") {
+		for _, field := range splitList(values.Get("fields")) {
+			if !slices.Contains(s.Fields, field) {
+				ferr["fields"] = oneOf(s.Fields)
+				break
+			}
+			q.Fields = append(q.Fields, field)
+		}
+		if len(q.Fields) > 0 && !slices.Contains(q.Fields, q.Key) {
+			// cursor paging needs the key
+			q.Fields = append(q.Fields, q.Key)
+		}
+	}
+
+	if len(ferr) > 0 {
+		return q, ferr
+	}
+	return q, nil
+}
+
+func parseFilter(field string, op Op, kind Kind, v string) (Filter, error) {
+	f := Filter{Field: field, Op: op}
+	switch op {
+	case In, Nin:
+		for _, s := range splitList(v) {
+			val, err := parseValue(kind, s)
+			if err != nil {
+				return f, err
+			}
+			f.Values = append(f.Values, val)
+		}
+		if len(f.Values) == 0 {
+			return f, phoenix.ValidationError{Code: "required", Message: "is required"}
+		}
+	case Null:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, typeError(Bool)
+		}
+		f.Values = []any{b}
+	case Between:
+		lo, hi, ok := strings.Cut(v, ",")
+		if !ok && lo == "" {
+			return f, invalid("between must be min,max")
+		}
+		for _, s := range []string{lo, hi} {
+			if s == "" {
+				f.Values = append(f.Values, nil)
+				continue
+			}
+			val, err := parseValue(kind, strings.TrimSpace(s))
+			if err != nil {
+				return f, err
+			}
+			f.Values = append(f.Values, val)
+		}
+	case Like:
+		if kind != String {
+			return f, invalid("like is only for strings")
+		}
+		f.Values = []any{"%" + likeEscaper.Replace(v) + "%"}
+	default:
+		val, err := parseValue(kind, v)
+		if err != nil {
+			return f, err
+		}
+		f.Values = []any{val}
+	}
+	return f, nil
+}
+
+func parseValue(kind Kind, s string) (any, error) {
+	var (
+		v   any
+		err error
+	)
+	switch kind {
+	case Int:
+		v, err = strconv.ParseInt(s, 10, 64)
+	case Float:
+		v, err = strconv.ParseFloat(s, 64)
+	case Bool:
+		v, err = strconv.ParseBool(s)
+	case Time:
+		if v, err = time.Parse(time.RFC3339, s); err != nil {
+			v, err = time.ParseInLocation(time.DateOnly, s, time.Local)
+		}
+	default:
+		v = s
+	}
+	if err != nil {
+		return nil, typeError(kind)
+	}
+	return v, nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func typeError(kind Kind) error {
+	return phoenix.ValidationError{
+		Code:    "type",
+		Message: "must be " + kind.String(),
+		Params:  map[string]any{"type": kind.String()},
+	}
+}
+
+func invalid(format string, args ...any) error {
+	return phoenix.ValidationError{Code: "invalid", Message: fmt.Sprintf(format, args...)}
+}
+
+// Escape wildcards of LIKE, so the value is matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func oneOf[T ~string](allowed []T) error {
+	list := make([]string, len(allowed))
+	for i, v := range allowed {
+		list[i] = string(v)
+	}
+	param := strings.Join(list, ", ")
+	return phoenix.ValidationError{
+		Code:    "oneof",
+		Message: "must be one of " + param,
+		Params:  map[string]any{"param": param},
+	}
+}
+
+// Queriers of filters, sorting and field selection.
+func (q Query) Queriers() []rel.Querier {
+	var queriers []rel.Querier
+	for _, f := range q.Filters {
+		if fq, ok := f.query(); ok {
+			queriers = append(queriers, fq)
+		}
+	}
+	for _, s := range q.Sorts {
+		if s.Desc {
+			queriers = append(queriers, rel.SortDesc(s.Field))
+		} else {
+			queriers = append(queriers, rel.SortAsc(s.Field))
+		}
+	}
+	if len(q.Fields) > 0 {
+		queriers = append(queriers, rel.Select(q.Fields...))
+	}
+	return queriers
+}
+
+func (f Filter) query() (rel.FilterQuery, bool) {
+	if len(f.Values) == 0 {
+		return rel.FilterQuery{}, false
+	}
+	v := f.Values[0]
+	switch f.Op {
+	case Ne:
+		return rel.Ne(f.Field, v), true
+	case Lt:
+		return rel.Lt(f.Field, v), true
+	case Lte:
+		return rel.Lte(f.Field, v), true
+	case Gt:
+		return rel.Gt(f.Field, v), true
+	case Gte:
+		return rel.Gte(f.Field, v), true
+	case In:
+		return rel.In(f.Field, f.Values...), true
+	case Nin:
+		return rel.Nin(f.Field, f.Values...), true
+	case Like:
+		return rel.Like(f.Field, v.(string)), true
+	case Null:
+		if v.(bool) {
+			return rel.Nil(f.Field), true
+		}
+		return rel.NotNil(f.Field), true
+	case Between:
+		var conds []rel.FilterQuery
+		if v != nil {
+			conds = append(conds, rel.Gte(f.Field, v))
+		}
+		if len(f.Values) > 1 && f.Values[1] != nil {
+			conds = append(conds, rel.Lte(f.Field, f.Values[1]))
+		}
+		if len(conds) == 0 {
+			return rel.FilterQuery{}, false
+		}
+		return rel.And(conds...), true
+	default:
+		return rel.Eq(f.Field, v), true
+	}
+}
+
+// Find a page of T by q, queriers are applied after q.
+//
+// Usage:
+//
+//	q, err := query.FromRequest(r, accounts.UserSchema)
+//	page, err := query.Find[model.User](ctx, repo.Repo, q, where.Eq("active", true))
+func Find[T any](ctx context.Context, repo rel.Repository, q Query, queriers ...rel.Querier) (paginate.Page[T], error) {
+	return paginate.Find[T](ctx, repo, q.Params, append(q.Queriers(), queriers...)...)
+}
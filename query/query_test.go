@@ -0,0 +1,62 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+)
+
+var schema = Schema{
+	Filters: map[string]Kind{"name": String, "age": Int, "deleted_at": Time},
+	Sorts:   []string{"id", "age"},
+	Fields:  []string{"id", "name"},
+	Sort:    "-id",
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		query string
+		want  rel.Query
+	}{
+		{"", rel.Build("", rel.SortDesc("id"))},
+		{"name=foo&locale=en", rel.Build("", where.Eq("name", "foo"), rel.SortDesc("id"))},
+		{"age[gte]=18&sort=age", rel.Build("", where.Gte("age", int64(18)), rel.SortAsc("age"))},
+		{"age[in]=1,2&sort=", rel.Build("", where.In("age", int64(1), int64(2)))},
+		{"name[like]=jo&sort=", rel.Build("", where.Like("name", "%jo%"))},
+		{"name[like]=john_doe&sort=", rel.Build("", where.Like("name", `%john\_doe%`))},
+		{"name[like]=50%25%5C&sort=", rel.Build("", where.Like("name", `%50\%\\%`))},
+		{"deleted_at[null]=true&sort=", rel.Build("", where.Nil("deleted_at"))},
+		{"age[between]=18,&sort=", rel.Build("", where.And(where.Gte("age", int64(18))))},
+		{"fields=name&sort=", rel.Build("", rel.Select("name", "id"))},
+	}
+	for _, c := range cases {
+		values, _ := url.ParseQuery(c.query)
+		q, err := Parse(values, schema)
+		if err != nil {
+			t.Errorf("%s: %v", c.query, err)
+			continue
+		}
+		if got := rel.Build("", q.Queriers()...); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s:\n got %#v\nwant %#v", c.query, got, c.want)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	values, _ := url.ParseQuery("age[gte]=x&email[eq]=a&name[foo]=b&sort=name&fields=age&page=0")
+	_, err := Parse(values, schema)
+	var ferr phoenix.FieldError
+	if !errors.As(err, &ferr) {
+		t.Fatalf("want FieldError, got %v", err)
+	}
+	for _, key := range []string{"age[gte]", "email[eq]", "name[foo]", "sort", "fields", "page"} {
+		if ferr[key] == nil {
+			t.Errorf("no error of %s in %v", key, ferr)
+		}
+	}
+}
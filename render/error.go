@@ -0,0 +1,131 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/env"
+	"github.com/DOVECYJ/phoenix/i18n"
+	"github.com/a-h/templ"
+)
+
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem is the problem details object defined by RFC 7807.
+type Problem struct {
+	Type     string                             `json:"type"`
+	Title    string                             `json:"title"`
+	Status   int                                `json:"status"`
+	Detail   string                             `json:"detail,omitempty"`
+	Instance string                             `json:"instance,omitempty"`
+	Code     int                                `json:"code,omitempty"`   // business error code
+	Errors   map[string]phoenix.ValidationError `json:"errors,omitempty"` // field errors
+}
+
+// Build a Problem from err by the error registry of phoenix, r is used to
+// fill the instance and can be nil. The message of an unknown error is only
+// shown when it is not running in prod environment. Title, detail and field
+// errors are translated into the locale of r.
+func NewProblem(r *http.Request, err error) Problem {
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+	info := phoenix.LookupError(err)
+	p := Problem{
+		Type:   "about:blank",
+		Title:  i18n.T(ctx, http.StatusText(info.Status)),
+		Status: info.Status,
+		Detail: i18n.T(ctx, info.Msg),
+		Code:   info.Code,
+		Errors: i18n.ValidationErrors(ctx, err),
+	}
+	if info.Msg == err.Error() {
+		// no registered message, translate the error itself
+		p.Detail = i18n.Error(ctx, err)
+	}
+	if info.Status >= http.StatusInternalServerError && !env.IsProd() {
+		p.Detail = err.Error()
+	}
+	if r != nil {
+		p.Instance = r.URL.Path
+	}
+	return p
+}
+
+// Render p to w as application/problem+json.
+func (p Problem) Render(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", MIMEProblemJSON)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
+
+// ErrorPage build the component of HTML error page, replace it to use your
+// own error page.
+var ErrorPage = func(p Problem) templ.Component {
+	return templ.ComponentFunc(func(_ context.Context, w io.Writer) error {
+		_, err := fmt.Fprintf(w, errorPageTemplate,
+			p.Status, html.EscapeString(p.Title),
+			p.Status, html.EscapeString(p.Title),
+			html.EscapeString(p.Detail))
+		return err
+	})
+}
+
+const errorPageTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"/><title>%d %s</title></head>
+<body><h1>%d %s</h1><p>%s</p></body>
+</html>
+`
+
+// Render err to w. The status, code and message is looked up in the error
+// registry of phoenix. It will render a HTML error page when client accept
+// text/html, otherwise application/problem+json. The status can be overridden
+// by Status Option.
+func Error(w http.ResponseWriter, r *http.Request, err error, opts ...Option) {
+	p := NewProblem(r, err)
+	if p.Status >= http.StatusInternalServerError {
+		slog.Error("render", "error", err)
+	}
+	opts = append([]Option{Status(p.Status)}, opts...)
+	if r != nil && acceptHTML(r) {
+		prepare(w, "text/html; charset=utf-8", opts).write(func(w io.Writer) error {
+			return ErrorPage(p).Render(r.Context(), w)
+		})
+		return
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	prepare(w, MIMEProblemJSON, opts).write(func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(p)
+	})
+}
+
+func acceptHTML(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		switch strings.TrimSpace(filterFlags(accept)) {
+		case "text/html", "application/xhtml+xml":
+			return true
+		case "application/json", MIMEProblemJSON:
+			return false
+		}
+	}
+	return false
+}
+
+func filterFlags(content string) string {
+	for i, char := range content {
+		if char == ' ' || char == ';' {
+			return content[:i]
+		}
+	}
+	return content
+}
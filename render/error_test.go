@@ -0,0 +1,94 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/env"
+	"github.com/spf13/viper"
+)
+
+func setEnv(t *testing.T, name string) {
+	viper.Set("env", name)
+	viper.Set("service", "render")
+	if err := env.ConfigEnv(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		viper.Set("env", "dev")
+		env.ConfigEnv()
+	})
+}
+
+func TestErrorNegotiation(t *testing.T) {
+	cases := []struct {
+		accept string
+		ct     string
+	}{
+		{"", MIMEProblemJSON},
+		{"text/html,application/xhtml+xml;q=0.9", "text/html; charset=utf-8"},
+		{"application/xhtml+xml", "text/html; charset=utf-8"},
+		{"application/json, text/html", MIMEProblemJSON},
+		{"application/problem+json", MIMEProblemJSON},
+		{"*/*", MIMEProblemJSON},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/posts/1", nil)
+		r.Header.Set("Accept", c.accept)
+		w := httptest.NewRecorder()
+		Error(w, r, phoenix.ErrCode(10001).WithMsg("<b>bad</b>"))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%q: code = %d", c.accept, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != c.ct {
+			t.Errorf("%q: content type = %s, want %s", c.accept, ct, c.ct)
+			continue
+		}
+		body := w.Body.String()
+		if c.ct == MIMEProblemJSON {
+			var p Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+				t.Fatal(err)
+			}
+			want := Problem{Type: "about:blank", Title: "Bad Request", Status: 400, Detail: "<b>bad</b>", Instance: "/posts/1", Code: 10001}
+			if p.Type != want.Type || p.Title != want.Title || p.Status != want.Status ||
+				p.Detail != want.Detail || p.Instance != want.Instance || p.Code != want.Code {
+				t.Errorf("%q: problem = %+v", c.accept, p)
+			}
+		} else if !strings.Contains(body, "<h1>400 Bad Request</h1>") || !strings.Contains(body, "&lt;b&gt;bad&lt;/b&gt;") {
+			t.Errorf("%q: page = %s", c.accept, body)
+		}
+	}
+}
+
+func TestErrorStatusOption(t *testing.T) {
+	w := httptest.NewRecorder()
+	Error(w, httptest.NewRequest("GET", "/", nil), errors.New("boom"), Status(http.StatusBadGateway))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("code = %d", w.Code)
+	}
+}
+
+func TestErrorDetail(t *testing.T) {
+	cases := []struct {
+		env    string
+		err    error
+		detail string
+	}{
+		{"dev", errors.New("dial tcp: connection refused"), "dial tcp: connection refused"},
+		{"test", errors.New("dial tcp: connection refused"), "dial tcp: connection refused"},
+		{"prod", errors.New("dial tcp: connection refused"), "Internal Server Error"},
+		{"prod", phoenix.ErrCode(10001).WithMsg("bad"), "bad"},
+	}
+	for _, c := range cases {
+		setEnv(t, c.env)
+		if p := NewProblem(nil, c.err); p.Detail != c.detail || p.Instance != "" {
+			t.Errorf("%s %v: detail = %q, instance = %q", c.env, c.err, p.Detail, p.Instance)
+		}
+	}
+}
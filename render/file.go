@@ -0,0 +1,217 @@
+package render
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var ErrUnsupportedSource = errors.New("unsupported file source")
+
+// Reader of an object in storage, it's a subset of oss.OssReader, so a client
+// of the oss package can be served as a file source.
+type ObjectReader interface {
+	Read() (io.ReadCloser, error)
+}
+
+// ModTime is an Option that set the modification time of a file, it is used
+// for Last-Modified and If-Modified-Since. Local file use its own mod time.
+func ModTime(t time.Time) Option {
+	return optionFunc(func(res *response) {
+		res.modTime = t
+	})
+}
+
+// Serve src inline, the browser will display it when possible.
+//
+// src can be a local file path, an io.ReadSeeker or an oss.OssReader. A
+// seekable source supports Range, If-Range, If-None-Match and
+// If-Modified-Since, so resumable downloads work. An oss.OssReader that is not
+// seekable is streamed as a whole. Content-Type is detected by file extension
+// or content sniffing.
+//
+// Never pass a path from client without cleaning it.
+func File(w http.ResponseWriter, r *http.Request, src any, opts ...Option) {
+	serveFile(w, r, src, "", "inline", opts)
+}
+
+// Serve src as an attachment named filename, the browser will download it.
+// When filename is empty the base name of local file is used. Non-ASCII
+// filename is supported by RFC 6266.
+//
+// Usage:
+//
+//	render.Attachment(w, r, "/data/report.xlsx", "2024年报表.xlsx")
+func Attachment(w http.ResponseWriter, r *http.Request, src any, filename string, opts ...Option) {
+	serveFile(w, r, src, filename, "attachment", opts)
+}
+
+func serveFile(w http.ResponseWriter, r *http.Request, src any, name, disposition string, opts []Option) {
+	res := prepare(w, "", opts)
+	switch src := src.(type) {
+	case string:
+		f, err := os.Open(src)
+		if err != nil {
+			res.fail(r, err)
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			res.fail(r, err)
+			return
+		}
+		if info.IsDir() {
+			res.fail(r, os.ErrNotExist)
+			return
+		}
+		if name == "" {
+			name = info.Name()
+		}
+		if res.modTime.IsZero() {
+			res.modTime = info.ModTime()
+		}
+		if w.Header().Get("ETag") == "" {
+			w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+		}
+		setDisposition(w, disposition, name)
+		http.ServeContent(w, r, name, res.modTime, f)
+	case io.ReadSeeker:
+		if f, ok := src.(interface{ Stat() (fs.FileInfo, error) }); ok && res.modTime.IsZero() {
+			if info, err := f.Stat(); err == nil {
+				res.modTime = info.ModTime()
+			}
+		}
+		setDisposition(w, disposition, name)
+		http.ServeContent(w, r, name, res.modTime, src)
+	case ObjectReader:
+		rc, err := src.Read()
+		if err != nil {
+			res.fail(r, err)
+			return
+		}
+		defer rc.Close()
+		if rs, ok := rc.(io.ReadSeeker); ok {
+			setDisposition(w, disposition, name)
+			http.ServeContent(w, r, name, res.modTime, rs)
+			return
+		}
+		serveStream(w, r, res, name, disposition, rc)
+	default:
+		res.fail(r, ErrUnsupportedSource)
+	}
+}
+
+// Send err with the header before options, so headers of the file, like
+// ETag and Cache-Control, are not cached with the error.
+func (res *response) fail(r *http.Request, err error) {
+	res.reset()
+	Error(res.w, r, err)
+}
+
+// Serve a not seekable reader, Range is ignored.
+func serveStream(w http.ResponseWriter, r *http.Request, res *response, name, disposition string, rd io.Reader) {
+	h := w.Header()
+	if etag := h.Get("ETag"); etag != "" && etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if !res.modTime.IsZero() {
+		h.Set("Last-Modified", res.modTime.UTC().Format(http.TimeFormat))
+	}
+	br := bufio.NewReaderSize(rd, 512)
+	if h.Get("Content-Type") == "" {
+		ctype := mime.TypeByExtension(filepath.Ext(name))
+		if ctype == "" {
+			head, _ := br.Peek(512)
+			ctype = http.DetectContentType(head)
+		}
+		h.Set("Content-Type", ctype)
+	}
+	h.Set("Accept-Ranges", "none")
+	setDisposition(w, disposition, name)
+	w.WriteHeader(res.status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, br); err != nil {
+		slog.Error("render file", "error", err)
+	}
+}
+
+func etagMatch(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func setDisposition(w http.ResponseWriter, disposition, name string) {
+	if name == "" && disposition == "inline" {
+		return
+	}
+	w.Header().Set("Content-Disposition", ContentDisposition(disposition, name))
+}
+
+// Build Content-Disposition header by RFC 6266. A filename with non-ASCII
+// characters, quotes or backslashes is encoded into filename* with UTF-8, and
+// an ASCII fallback is kept in filename for old clients.
+//
+//	ContentDisposition("attachment", "报表.xlsx")
+//	// attachment; filename="__.xlsx"; filename*=UTF-8''%E6%8A%A5%E8%A1%A8.xlsx
+func ContentDisposition(disposition, filename string) string {
+	if filename == "" {
+		return disposition
+	}
+	var fallback strings.Builder
+	ascii := true
+	for _, c := range filename {
+		switch {
+		case c == '"' || c == '\\', c < 0x20, c >= 0x7f:
+			// quoted-pair is not handled well by browsers, the real name
+			// is kept in filename*
+			fallback.WriteByte('_')
+			ascii = false
+		default:
+			fallback.WriteRune(c)
+		}
+	}
+	s := fmt.Sprintf(`%s; filename="%s"`, disposition, fallback.String())
+	if !ascii {
+		s += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return s
+}
+
+// Percent encode s except attr-char in RFC 5987.
+func encodeRFC5987(s string) string {
+	var sb strings.Builder
+	for _, b := range []byte(s) {
+		if isAttrChar(b) {
+			sb.WriteByte(b)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+	return sb.String()
+}
+
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
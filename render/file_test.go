@@ -0,0 +1,109 @@
+package render
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestContentDisposition(t *testing.T) {
+	cases := []struct {
+		disposition, filename, want string
+	}{
+		{"inline", "", "inline"},
+		{"attachment", "report.xlsx", `attachment; filename="report.xlsx"`},
+		{"attachment", "报表.xlsx", `attachment; filename="__.xlsx"; filename*=UTF-8''%E6%8A%A5%E8%A1%A8.xlsx`},
+		{"attachment", `a"b\c.txt`, `attachment; filename="a_b_c.txt"; filename*=UTF-8''a%22b%5Cc.txt`},
+		{"attachment", "a\nb.txt", `attachment; filename="a_b.txt"; filename*=UTF-8''a%0Ab.txt`},
+		{"attachment", "a b;c.txt", `attachment; filename="a b;c.txt"`},
+	}
+	for _, c := range cases {
+		if got := ContentDisposition(c.disposition, c.filename); got != c.want {
+			t.Errorf("ContentDisposition(%q, %q) = %s, want %s", c.disposition, c.filename, got, c.want)
+		}
+	}
+}
+
+// A not seekable object.
+type object string
+
+func (o object) Read() (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(string(o))), nil
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	File(w, httptest.NewRequest("GET", "/", nil), path)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || w.Body.String() != "0123456789" {
+		t.Fatalf("file: %d %q %q", w.Code, etag, w.Body)
+	}
+
+	cases := []struct {
+		name   string
+		src    any
+		header map[string]string
+		opts   []Option
+		status int
+		body   string
+	}{
+		{"range", path, map[string]string{"Range": "bytes=2-4"}, nil, http.StatusPartialContent, "234"},
+		{"if-range matched", path, map[string]string{"Range": "bytes=2-4", "If-Range": etag}, nil, http.StatusPartialContent, "234"},
+		{"if-range changed", path, map[string]string{"Range": "bytes=2-4", "If-Range": `"old"`}, nil, http.StatusOK, "0123456789"},
+		{"if-none-match", path, map[string]string{"If-None-Match": etag}, nil, http.StatusNotModified, ""},
+		{"seeker range", strings.NewReader("abcdef"), map[string]string{"Range": "bytes=-2"}, nil, http.StatusPartialContent, "ef"},
+		{"range not satisfiable", path, map[string]string{"Range": "bytes=20-"}, nil, http.StatusRequestedRangeNotSatisfiable, ""},
+		{"object range ignored", object("abcdef"), map[string]string{"Range": "bytes=0-1"}, nil, http.StatusOK, "abcdef"},
+		{"object etag", object("abcdef"), map[string]string{"If-None-Match": `W/"v1"`}, []Option{Header("ETag", `"v1"`)}, http.StatusNotModified, ""},
+		{"not found", filepath.Join(filepath.Dir(path), "b.txt"), nil, nil, http.StatusNotFound, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			for k, v := range c.header {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			File(w, r, c.src, c.opts...)
+			if w.Code != c.status {
+				t.Fatalf("status = %d, want %d", w.Code, c.status)
+			}
+			if c.body != "" && w.Body.String() != c.body {
+				t.Errorf("body = %q, want %q", w.Body, c.body)
+			}
+		})
+	}
+}
+
+func TestFileNotFound(t *testing.T) {
+	w := httptest.NewRecorder()
+	File(w, httptest.NewRequest("GET", "/", nil), filepath.Join(t.TempDir(), "a.txt"),
+		CacheControl("public", "max-age=31536000", "immutable"), ETag("v1"))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d", w.Code)
+	}
+	for _, key := range []string{"Cache-Control", "ETag"} {
+		if v := w.Header().Get(key); v != "" {
+			t.Errorf("%s = %q on error", key, v)
+		}
+	}
+}
+
+func TestAttachment(t *testing.T) {
+	w := httptest.NewRecorder()
+	Attachment(w, httptest.NewRequest("GET", "/", nil), object("a,b"), "报表.csv")
+	if got := w.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="__.csv"; filename*=`) {
+		t.Errorf("disposition = %s", got)
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Errorf("content type = %s", got)
+	}
+}
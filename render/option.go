@@ -0,0 +1,74 @@
+package render
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Option is applied to the response before the body is written, so the order
+// of options does not matter.
+//
+// Usage:
+//
+//	render.JSON(w, data, render.Status(201), render.Location("/users/1"))
+type Option interface {
+	apply(*response)
+}
+
+// Status is an Option that set http status code.
+type Status int
+
+func (s Status) apply(res *response) {
+	res.status = int(s)
+}
+
+// Cookie is an Option that write http cookie.
+type Cookie http.Cookie
+
+func (c *Cookie) apply(res *response) {
+	http.SetCookie(res.w, (*http.Cookie)(c))
+}
+
+type optionFunc func(*response)
+
+func (fn optionFunc) apply(res *response) {
+	fn(res)
+}
+
+// Header is an Option that set http header.
+func Header(key, value string) Option {
+	return optionFunc(func(res *response) {
+		res.w.Header().Set(key, value)
+	})
+}
+
+// CacheControl is an Option that set Cache-Control header by directives.
+//
+//	CacheControl("public", "max-age=3600")
+func CacheControl(directives ...string) Option {
+	return Header("Cache-Control", strings.Join(directives, ", "))
+}
+
+// NoStore is an Option that forbid any cache of the response.
+var NoStore = CacheControl("no-store")
+
+// ETag is an Option that set ETag header, tag will be quoted when it is not.
+// Weak tag like W/"abc" is kept as it is.
+func ETag(tag string) Option {
+	if !strings.HasPrefix(tag, `"`) && !strings.HasPrefix(tag, `W/"`) {
+		tag = `"` + tag + `"`
+	}
+	return Header("ETag", tag)
+}
+
+// Location is an Option that set Location header.
+func Location(url string) Option {
+	return Header("Location", url)
+}
+
+// Buffered is an Option that encode the body into memory before sending, so
+// a failed encoding can still response a clean 500 instead of a half-written
+// 200.
+var Buffered Option = optionFunc(func(res *response) {
+	res.buffered = true
+})
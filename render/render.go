@@ -1,100 +1,102 @@
 package render
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/DOVECYJ/phoenix"
 	"github.com/a-h/templ"
 )
 
-type option interface {
-	apply(http.ResponseWriter)
-}
-
-// Status is an option that set http status code.
-type Status int
-
-func (s Status) apply(w http.ResponseWriter) {
-	w.WriteHeader(int(s))
-}
-
-// Cookie is an option that write http cookie.
-type Cookie http.Cookie
-
-func (c *Cookie) apply(w http.ResponseWriter) {
-	http.SetCookie(w, (*http.Cookie)(c))
-}
-
 type render interface {
 	Render(w http.ResponseWriter) error
 }
 
-// Render data to w with opts.
-func Render(w http.ResponseWriter, data any) {
+// Render data to w with opts. An error is rendered as problem+json, its status
+// is looked up in the error registry of phoenix.
+func Render(w http.ResponseWriter, data any, opts ...Option) {
 	switch data := data.(type) {
 	default:
-		JSON(w, data)
-	case phoenix.CodeError:
-		JSON(w, phoenix.ApiResponse{Code: data.Code(), Msg: data.Error()})
+		JSON(w, data, opts...)
 	case templ.Component:
-		HTML(w, data)
+		HTML(w, nil, data, opts...)
 	case string:
-		String(w, data)
+		String(w, data, opts...)
 	case []byte:
-		Bytes(w, data)
+		Bytes(w, data, opts...)
 	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
-		prinltln(w, data)
+		prinltln(w, opts, data)
 	case render:
-		handleError(w, data.Render(w))
+		// the status is decided by the render itself
+		prepare(w, "", opts).handleError(data.Render(w))
 	case error:
-		http.Error(w, data.Error(), http.StatusInternalServerError)
+		Error(w, nil, data, opts...)
 	}
 }
 
-// Render HTML component to w.
-func HTML(w http.ResponseWriter, component templ.Component) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := component.Render(context.Background(), w); err != nil {
-		handleError(w, err)
+// Render HTML component to w. The component is rendered with the context of r,
+// so it can read assigns by phoenix.CurrentUser(ctx), phoenix.CSRFToken(ctx)
+// and phoenix.Flash(ctx). r can be nil when the component needs no request.
+func HTML(w http.ResponseWriter, r *http.Request, component templ.Component, opts ...Option) {
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
 	}
+	ctx, _ = phoenix.EnsureAssigns(ctx)
+	prepare(w, "text/html; charset=utf-8", opts).write(func(w io.Writer) error {
+		return component.Render(ctx, w)
+	})
 }
 
 // Render data to w in json format.
-func JSON(w http.ResponseWriter, data any) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		handleError(w, err)
-	}
+func JSON(w http.ResponseWriter, data any, opts ...Option) {
+	prepare(w, "application/json; charset=utf-8", opts).write(func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(data)
+	})
 }
 
-func ApiData(w http.ResponseWriter, data any) {
-	JSON(w, phoenix.ApiResponse{Data: data})
+// Render data to w use ApiReponse.
+func ApiData(w http.ResponseWriter, data any, opts ...Option) {
+	JSON(w, phoenix.ApiResponse{Data: data}, opts...)
 }
 
 // Render code and error to w use ApiReponse.
-func ApiError(w http.ResponseWriter, code int, err error) {
-	JSON(w, phoenix.ApiResponse{Code: code, Msg: err.Error()})
+func ApiError(w http.ResponseWriter, code int, err error, opts ...Option) {
+	JSON(w, phoenix.ApiResponse{Code: code, Msg: err.Error()}, opts...)
 }
 
 // Send byte slice to w.
-func Bytes(w http.ResponseWriter, data []byte) {
-	// w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	w.Write(data)
+func Bytes(w http.ResponseWriter, data []byte, opts ...Option) {
+	prepare(w, "", opts).write(func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
 }
 
 // Send string to w.
-func String(w http.ResponseWriter, data string) {
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	fmt.Fprintln(w, data)
+func String(w http.ResponseWriter, data string, opts ...Option) {
+	prinltln(w, opts, data)
+}
+
+func prinltln(w http.ResponseWriter, opts []Option, args ...any) {
+	prepare(w, "text/plain; charset=utf-8", opts).write(func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, args...)
+		return err
+	})
 }
 
-func prinltln(w http.ResponseWriter, args ...any) {
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	fmt.Fprintln(w, args...)
+// Send 204 No Content, options like Header and Cookie are applied, but the
+// status is always 204.
+func NoContent(w http.ResponseWriter, opts ...Option) {
+	opts = append(opts[:len(opts):len(opts)], Status(http.StatusNoContent))
+	prepare(w, "", opts).write(func(io.Writer) error { return nil })
 }
 
 func HttpStatus(w http.ResponseWriter, code int) http.ResponseWriter {
@@ -102,9 +104,71 @@ func HttpStatus(w http.ResponseWriter, code int) http.ResponseWriter {
 	return w
 }
 
-func handleError(w http.ResponseWriter, err error) {
+// response hold the options before the body is written.
+type response struct {
+	w             http.ResponseWriter
+	status        int
+	buffered      bool
+	flushEvery    int           // stream only
+	flushInterval time.Duration // stream only
+	modTime       time.Time     // file only
+	header        http.Header   // header before options, restored on error
+}
+
+// Set content type and apply opts to the header of w. The status code is not
+// written until write is called.
+func prepare(w http.ResponseWriter, contentType string, opts []Option) *response {
+	res := &response{w: w, status: http.StatusOK, header: w.Header().Clone()}
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	for i := range opts {
+		opts[i].apply(res)
+	}
+	return res
+}
+
+// Write status code and body. In buffered mode the body is encoded before
+// anything is sent, so an encoding failure still produce a clean 500.
+func (res *response) write(body func(io.Writer) error) {
+	if !res.buffered {
+		res.w.WriteHeader(res.status)
+		if err := body(res.w); err != nil {
+			// the status is already sent, nothing can do but log it
+			slog.Error("render", "error", err)
+		}
+		return
+	}
+	var buf bytes.Buffer
+	if err := body(&buf); err != nil {
+		res.handleError(err)
+		return
+	}
+	res.w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	res.w.WriteHeader(res.status)
+	if _, err := buf.WriteTo(res.w); err != nil {
+		slog.Error("render", "error", err)
+	}
+}
+
+// Send 500 for err. Headers set by options, like ETag, Cache-Control,
+// Location and cookies, are dropped, since they describe the response that
+// failed.
+func (res *response) handleError(err error) {
 	if err != nil {
 		slog.Error("render", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		res.reset()
+		http.Error(res.w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Restore the header to what it was before options.
+func (res *response) reset() {
+	h := res.w.Header()
+	for k := range h {
+		delete(h, k)
+	}
+	for k, v := range res.header {
+		h[k] = v
 	}
 }
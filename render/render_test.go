@@ -0,0 +1,25 @@
+package render
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBufferedError(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("X-Request-Id", "1")
+	JSON(w, make(chan int), Buffered, Status(http.StatusCreated), ETag("v1"), NoStore,
+		Location("/users/1"), &Cookie{Name: "a", Value: "b"})
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("code = %d", w.Code)
+	}
+	for _, key := range []string{"ETag", "Cache-Control", "Location", "Set-Cookie", "Content-Length"} {
+		if v := w.Header().Get(key); v != "" {
+			t.Errorf("%s = %q, want none", key, v)
+		}
+	}
+	if v := w.Header().Get("X-Request-Id"); v != "1" {
+		t.Errorf("header before render is dropped")
+	}
+}
@@ -0,0 +1,160 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	MIMENDJSON = "application/x-ndjson"
+
+	defaultFlushEvery    = 100
+	defaultFlushInterval = time.Second
+)
+
+// Iterator yields items one by one, ok is false when there are no more items.
+// ctx is the context of request, it is done when client canceled.
+type Iterator[T any] func(ctx context.Context) (item T, ok bool, err error)
+
+// Iterate items from ch until it is closed or ctx is done.
+func FromChan[T any](ch <-chan T) Iterator[T] {
+	return func(ctx context.Context) (item T, ok bool, err error) {
+		select {
+		case item, ok = <-ch:
+			return item, ok, nil
+		case <-ctx.Done():
+			return item, false, ctx.Err()
+		}
+	}
+}
+
+// Iterate items in slice.
+func FromSlice[T any](items []T) Iterator[T] {
+	var i int
+	return func(context.Context) (item T, ok bool, err error) {
+		if i >= len(items) {
+			return item, false, nil
+		}
+		i++
+		return items[i-1], true, nil
+	}
+}
+
+// FlushEvery is an Option that flush the stream after n items are written.
+// The default is 100.
+func FlushEvery(n int) Option {
+	return optionFunc(func(res *response) {
+		res.flushEvery = n
+	})
+}
+
+// FlushInterval is an Option that flush the stream when d has passed since
+// last flush. The default is 1 second.
+func FlushInterval(d time.Duration) Option {
+	return optionFunc(func(res *response) {
+		res.flushInterval = d
+	})
+}
+
+// Stream items as newline delimited json. The next item is not pulled until
+// the previous one is written, so a slow client slows down the producer.
+// Streaming stops when client canceled the request. stop is called when
+// streaming ends for any reason, so the iterator can release its resources,
+// it can be nil.
+//
+// Usage:
+//
+//	next, stop := repo.Stream[model.User](repo.Repo, 500)
+//	render.StreamNDJSON(w, r, next, stop)
+func StreamNDJSON[T any](w http.ResponseWriter, r *http.Request, next Iterator[T], stop func(), opts ...Option) {
+	stream(w, r, MIMENDJSON, next, stop, opts, true)
+}
+
+// Stream items as a json array. Once streaming started, an error can only
+// be logged and the array is left unclosed, so client can tell it's broken.
+// stop is the same as StreamNDJSON.
+func StreamJSON[T any](w http.ResponseWriter, r *http.Request, next Iterator[T], stop func(), opts ...Option) {
+	stream(w, r, "application/json; charset=utf-8", next, stop, opts, false)
+}
+
+// Write items one per line when ndjson, otherwise as a json array.
+func stream[T any](w http.ResponseWriter, r *http.Request, contentType string, next Iterator[T], stop func(), opts []Option, ndjson bool) {
+	if stop != nil {
+		defer stop()
+	}
+	ctx := r.Context()
+	item, ok, err := next(ctx)
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if err != nil {
+		// nothing is written yet, still able to send an error
+		Error(w, r, err)
+		return
+	}
+
+	res := prepare(w, contentType, opts)
+	if res.flushEvery <= 0 {
+		res.flushEvery = defaultFlushEvery
+	}
+	if res.flushInterval <= 0 {
+		res.flushInterval = defaultFlushInterval
+	}
+	rc := http.NewResponseController(w)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(res.status)
+
+	err = func() error {
+		open, sep, end := []byte("["), []byte(","), []byte("]\n")
+		if ndjson {
+			open, sep, end = nil, nil, nil
+		}
+		if _, err := w.Write(open); err != nil {
+			return err
+		}
+		lastFlush := time.Now()
+		for n := 0; ok; n++ {
+			bs, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				if _, err := w.Write(sep); err != nil {
+					return err
+				}
+			}
+			if ndjson {
+				bs = append(bs, '\n')
+			}
+			if _, err := w.Write(bs); err != nil {
+				return err
+			}
+			if (n+1)%res.flushEvery == 0 || time.Since(lastFlush) >= res.flushInterval {
+				if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+					return err
+				}
+				lastFlush = time.Now()
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if item, ok, err = next(ctx); err != nil {
+				return err
+			}
+		}
+		_, err := w.Write(end)
+		return err
+	}()
+	switch {
+	case err == nil:
+	case errors.Is(err, context.Canceled):
+		slog.Info("stream canceled", "path", r.URL.Path)
+	default:
+		slog.Error("stream", "error", err)
+	}
+}
@@ -0,0 +1,51 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamStop(t *testing.T) {
+	cases := []struct {
+		name  string
+		items []any
+		want  string
+	}{
+		{"done", []any{1, 2}, "1\n2\n"},
+		{"marshal error", []any{1, make(chan int), 3}, "1\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stopped := 0
+			w := httptest.NewRecorder()
+			StreamNDJSON(w, httptest.NewRequest("GET", "/", nil), FromSlice(c.items), func() { stopped++ })
+			if got := w.Body.String(); got != c.want {
+				t.Errorf("body = %q, want %q", got, c.want)
+			}
+			if stopped != 1 {
+				t.Errorf("stop is called %d times", stopped)
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, MIMENDJSON) {
+				t.Errorf("content type = %s", ct)
+			}
+		})
+	}
+}
+
+func TestStreamStopOnError(t *testing.T) {
+	stopped := 0
+	failing := Iterator[int](func(context.Context) (int, bool, error) {
+		return 0, false, errors.New("query failed")
+	})
+	w := httptest.NewRecorder()
+	StreamJSON(w, httptest.NewRequest("GET", "/", nil), failing, func() { stopped++ })
+	if w.Code != http.StatusInternalServerError || stopped != 1 {
+		t.Errorf("code = %d, stop is called %d times", w.Code, stopped)
+	}
+	// stop can be nil
+	StreamJSON(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), FromSlice([]int{1}), nil)
+}
@@ -1,7 +1,9 @@
 package repo
 
 import (
+	"context"
 	"errors"
+	"io"
 
 	"github.com/go-rel/rel"
 )
@@ -43,3 +45,39 @@ func (d DriverFunc) Open(dsn string) (rel.Adapter, error) {
 func Register(driverName string, driver Driver) {
 	driverMap[driverName] = driver
 }
+
+// Stream read T from repo in batches of size, it returns an iterator that can
+// be passed to render.StreamNDJSON or render.StreamJSON. The records are
+// ordered by primary key, and the query is started at the first pull. stop
+// closes the query when the iteration ends early, pass it to the stream
+// functions of render or defer it. It's safe to call stop more than once.
+func Stream[T any](repo rel.Repository, size int, queriers ...rel.Querier) (next func(context.Context) (T, bool, error), stop func()) {
+	var iter rel.Iterator
+	stop = func() {
+		if iter != nil {
+			iter.Close()
+			iter = nil
+		}
+	}
+	done := false
+	next = func(ctx context.Context) (item T, ok bool, err error) {
+		if done {
+			return item, false, nil
+		}
+		if iter == nil {
+			var zero T
+			query := rel.Build(rel.NewDocument(&zero, true).Table(), queriers...)
+			iter = repo.Iterate(ctx, query, rel.BatchSize(size))
+		}
+		if err = iter.Next(&item); err != nil {
+			stop()
+			done = true
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			return item, false, err
+		}
+		return item, true, nil
+	}
+	return next, stop
+}
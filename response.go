@@ -11,6 +11,7 @@ type ApiResponse struct {
 	Code int    `json:"code"`
 	Msg  string `json:"msg"`
 	Data any    `json:"data"`
+	Meta any    `json:"meta,omitempty"` // metadata like paging
 }
 
 // error interface with a error code
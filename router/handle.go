@@ -0,0 +1,97 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"github.com/DOVECYJ/phoenix/binding"
+	"github.com/DOVECYJ/phoenix/render"
+)
+
+// A handler which knows types of its request and response, tools like api
+// docs read them from RouteInfo.
+type TypedHandler interface {
+	http.Handler
+	Types() (req, resp reflect.Type)
+}
+
+type typedHandler[Req, Resp any] struct {
+	fn   func(context.Context, Req) (Resp, error)
+	name string
+	opts []render.Option
+}
+
+// Make a handler of fn, it binds Req from path, query, header, cookie and
+// body by binding.BindAll, then calls fn and renders Resp by render.ApiData.
+// A Req which is not a struct is bound from json body. Errors of binding and
+// fn are rendered by render.Error, so the status is looked up in the error
+// registry of phoenix. A Resp of struct{} renders 204 No Content by
+// render.NoContent.
+//
+// Usage:
+//
+//	type CreatePostReq struct {
+//		Title string `json:"title" binding:"required"`
+//	}
+//
+//	func CreatePost(ctx context.Context, req CreatePostReq) (model.Post, error) {
+//		...
+//	}
+//
+//	r.Method(http.MethodPost, "/posts", router.Handle(CreatePost, render.Status(http.StatusCreated)))
+//
+// Options are used to render Resp. Name it by NamedHandler for URL.
+func Handle[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error), opts ...render.Option) http.Handler {
+	if fn == nil {
+		panic("handle function can not be nil")
+	}
+	return &typedHandler[Req, Resp]{fn: fn, name: funcName(fn), opts: opts}
+}
+
+func (h *typedHandler[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Req
+	var err error
+	if reflect.TypeOf((*Req)(nil)).Elem().Kind() == reflect.Struct {
+		err = binding.BindAll(r, &req)
+	} else {
+		err = binding.BindJSON(r, &req)
+	}
+	if err != nil {
+		render.Error(w, r, err)
+		return
+	}
+	resp, err := h.fn(r.Context(), req)
+	if err != nil {
+		render.Error(w, r, err)
+		return
+	}
+	if _, ok := any(resp).(struct{}); ok {
+		render.NoContent(w, h.opts...)
+		return
+	}
+	render.ApiData(w, resp, h.opts...)
+}
+
+// Types of Req and Resp.
+func (h *typedHandler[Req, Resp]) Types() (req, resp reflect.Type) {
+	return reflect.TypeOf((*Req)(nil)).Elem(), reflect.TypeOf((*Resp)(nil)).Elem()
+}
+
+func (h *typedHandler[Req, Resp]) String() string {
+	return h.name
+}
+
+// Status of a successful response, it's set by render.Status option.
+func (h *typedHandler[Req, Resp]) Status() int {
+	if _, ok := any(*new(Resp)).(struct{}); ok {
+		return http.StatusNoContent
+	}
+	status := http.StatusOK
+	for _, opt := range h.opts {
+		if s, ok := opt.(render.Status); ok {
+			status = int(s)
+		}
+	}
+	return status
+}
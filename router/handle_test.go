@@ -0,0 +1,103 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/render"
+	"github.com/go-chi/chi/v5"
+)
+
+type createPostReq struct {
+	UserID int    `path:"user_id"`
+	Title  string `json:"title" binding:"required"`
+}
+
+type post struct {
+	UserID int    `json:"user_id"`
+	Title  string `json:"title"`
+}
+
+var errPostNotFound = errors.New("post not found")
+
+func init() {
+	phoenix.RegisterError(errPostNotFound, http.StatusNotFound, 0, "")
+}
+
+func TestHandle(t *testing.T) {
+	create := func(ctx context.Context, req createPostReq) (post, error) {
+		if req.Title == "missing" {
+			return post{}, errPostNotFound
+		}
+		return post{UserID: req.UserID, Title: req.Title}, nil
+	}
+	remove := func(ctx context.Context, req struct{}) (struct{}, error) {
+		return struct{}{}, nil
+	}
+	r := chi.NewRouter()
+	r.Method(http.MethodPost, "/users/{user_id}/posts", Handle(create, render.Status(http.StatusCreated), render.Location("/posts/1")))
+	r.Method(http.MethodDelete, "/posts/{id}", Handle(remove, render.Status(http.StatusAccepted), render.Header("X-Deleted", "1"),
+		&render.Cookie{Name: "undo", Value: "1"}))
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		accept string
+		code   int
+		ctype  string
+		check  func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{"created", "POST", "/users/7/posts", `{"title":"hi"}`, "", http.StatusCreated, "application/json", func(t *testing.T, w *httptest.ResponseRecorder) {
+			var res struct{ Data post }
+			json.Unmarshal(w.Body.Bytes(), &res)
+			if res.Data != (post{UserID: 7, Title: "hi"}) || w.Header().Get("Location") != "/posts/1" {
+				t.Errorf("response %s %v", w.Body, w.Header())
+			}
+		}},
+		{"bind error", "POST", "/users/7/posts", `{}`, "", http.StatusUnprocessableEntity, render.MIMEProblemJSON, func(t *testing.T, w *httptest.ResponseRecorder) {
+			var p render.Problem
+			json.Unmarshal(w.Body.Bytes(), &p)
+			if _, ok := p.Errors["title"]; !ok || w.Header().Get("Location") != "" {
+				t.Errorf("problem %s %v", w.Body, w.Header())
+			}
+		}},
+		{"malformed", "POST", "/users/7/posts", `{"title":`, "", http.StatusBadRequest, render.MIMEProblemJSON, nil},
+		{"fn error", "POST", "/users/7/posts", `{"title":"missing"}`, "", http.StatusNotFound, render.MIMEProblemJSON, nil},
+		{"error page", "POST", "/users/7/posts", `{"title":"missing"}`, "text/html", http.StatusNotFound, "text/html", nil},
+		{"no content", "DELETE", "/posts/1", "", "", http.StatusNoContent, "", func(t *testing.T, w *httptest.ResponseRecorder) {
+			if w.Body.Len() != 0 || w.Header().Get("X-Deleted") != "1" || !strings.HasPrefix(w.Header().Get("Set-Cookie"), "undo=1") {
+				t.Errorf("response %q %v", w.Body, w.Header())
+			}
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+			if c.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if c.accept != "" {
+				req.Header.Set("Accept", c.accept)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != c.code {
+				t.Fatalf("code = %d, want %d: %s", w.Code, c.code, w.Body)
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, c.ctype) {
+				t.Errorf("content type = %q, want %q", ct, c.ctype)
+			}
+			if c.check != nil {
+				c.check(t, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// A named group of middlewares, like pipeline :browser of phoenix.
+type pipeline struct {
+	name string
+	mws  chi.Middlewares
+}
+
+var (
+	pipelineMu sync.RWMutex
+	pipelines  = map[string]*pipeline{}
+)
+
+// Define pipeline name of middlewares, so scopes can use them by name with
+// PipeThrough. Defining it again replaces it, routes piped through before
+// keep the old one.
+//
+// Usage:
+//
+//	router.Pipeline("browser", middleware.Assigns, flash.Fetch, middleware.CSRF)
+//	router.Pipeline("api", middleware.LoadUser(loadUser), chimiddleware.AllowContentType("application/json"))
+func Pipeline(name string, mws ...func(http.Handler) http.Handler) {
+	pipelineMu.Lock()
+	defer pipelineMu.Unlock()
+	pipelines[name] = &pipeline{name: name, mws: mws}
+}
+
+// Use pipelines by name in r, it's usually the router of a scope made by
+// Route or Group. Routes list names of their pipelines, and middlewares of
+// them in place. It panics when a pipeline is not defined.
+//
+// Usage:
+//
+//	root.Group(func(r chi.Router) {
+//		router.PipeThrough(r, "browser")
+//		r.Get("/", controllers.Index)
+//	})
+//	root.Route("/api", func(r chi.Router) {
+//		router.PipeThrough(r, "api")
+//		r.Route("/posts", router.Resource(controllers.PostController{}))
+//	})
+func PipeThrough(r chi.Router, names ...string) {
+	pipelineMu.RLock()
+	defer pipelineMu.RUnlock()
+	for _, name := range names {
+		p, ok := pipelines[name]
+		if !ok {
+			panic(fmt.Sprintf("router: pipeline %q is not defined", name))
+		}
+		r.Use(p.handler)
+	}
+}
+
+// Handler of pipeline with next, it's known by Routes through its name.
+type piped struct {
+	*pipeline
+	http.Handler
+}
+
+func (p *pipeline) handler(next http.Handler) http.Handler {
+	return piped{pipeline: p, Handler: chi.Chain(p.mws...).Handler(next)}
+}
+
+var pipelineHandler = funcName((&pipeline{}).handler)
+
+// Pipeline of middleware mw, or nil if mw is not one.
+func pipelineOf(mw func(http.Handler) http.Handler) *pipeline {
+	if funcName(mw) != pipelineHandler {
+		return nil
+	}
+	if p, ok := mw(http.NotFoundHandler()).(piped); ok {
+		return p.pipeline
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+func trace(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trace", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func traceA(next http.Handler) http.Handler { return trace("a")(next) }
+func traceB(next http.Handler) http.Handler { return trace("b")(next) }
+
+func TestPipeThrough(t *testing.T) {
+	Pipeline("test_browser", traceA, traceB)
+	Pipeline("test_api", trace("api"))
+	r := chi.NewRouter()
+	r.Use(chimiddleware.RequestID)
+	r.Group(func(r chi.Router) {
+		PipeThrough(r, "test_browser", "test_api")
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+	})
+	Pipeline("test_api", trace("api2")) // routes piped through keep the old one
+	r.Group(func(r chi.Router) {
+		PipeThrough(r, "test_api")
+		r.Get("/api", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	cases := []struct {
+		path  string
+		trace []string
+	}{
+		{"/", []string{"a", "b", "api"}},
+		{"/api", []string{"api2"}},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", c.path, nil))
+		if got := w.Header().Values("X-Trace"); !slices.Equal(got, c.trace) {
+			t.Errorf("%s: trace = %q, want %q", c.path, got, c.trace)
+		}
+	}
+
+	routes := Routes(r)
+	want := []struct {
+		pipelines   []string
+		middlewares []string
+	}{
+		{[]string{"test_browser", "test_api"}, []string{"middleware.RequestID", "router.traceA", "router.traceB", "router.trace"}},
+		{[]string{"test_api"}, []string{"middleware.RequestID", "router.trace"}},
+	}
+	for i, w := range want {
+		if !slices.Equal(routes[i].Pipelines, w.pipelines) || !slices.Equal(routes[i].Middlewares, w.middlewares) {
+			t.Errorf("%s: pipelines = %q, middlewares = %q", routes[i].Pattern, routes[i].Pipelines, routes[i].Middlewares)
+		}
+	}
+}
+
+func TestPipeThroughUndefined(t *testing.T) {
+	defer func() {
+		if got := recover(); got != `router: pipeline "test_none" is not defined` {
+			t.Errorf("panic = %v", got)
+		}
+	}()
+	PipeThrough(chi.NewRouter(), "test_none")
+}
+
+func TestPipelineOf(t *testing.T) {
+	Pipeline("test_of", traceA)
+	r := chi.NewRouter()
+	PipeThrough(r, "test_of")
+	cases := []struct {
+		name string
+		mw   func(http.Handler) http.Handler
+		want string
+	}{
+		{"pipeline", r.Middlewares()[0], "test_of"},
+		{"func", traceA, ""},
+		{"closure", trace("a"), ""},
+		{"other method value", (&pipeline{name: "x"}).handler, "x"}, // same func, so it's a pipeline too
+		{"chi", chimiddleware.RequestID, ""},
+	}
+	for _, c := range cases {
+		var got string
+		if p := pipelineOf(c.mw); p != nil {
+			got = p.name
+		}
+		if got != c.want {
+			t.Errorf("%s: pipeline = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/DOVECYJ/phoenix/middleware"
+	"github.com/azer/snakecase"
+	"github.com/go-chi/chi/v5"
+	"github.com/jinzhu/inflection"
+)
+
+// A RESTful action interface
+type IResource interface {
+	Index(http.ResponseWriter, *http.Request)  // index: show a list of object
+	Edit(http.ResponseWriter, *http.Request)   // edit: show edit form
+	New(http.ResponseWriter, *http.Request)    // new: show create object form
+	Show(http.ResponseWriter, *http.Request)   // show: show one object detail by id
+	Create(http.ResponseWriter, *http.Request) // create: save a new object
+	Update(http.ResponseWriter, *http.Request) // update: save update object
+	Delete(http.ResponseWriter, *http.Request) // delete: delete a object by id
+}
+
+type Resources struct {
+	IResource
+	ID middleware.IDKind // kind of id in url, default is int
+}
+
+func (s Resources) Route(r chi.Router) {
+	Resource(s, WithID(s.ID))(r)
+}
+
+func (s Resources) Only(r chi.Router, actions ...string) {
+	Resource(s, WithID(s.ID), Only(actions...))(r)
+}
+
+func (s Resources) Except(r chi.Router, actions ...string) {
+	Resource(s, WithID(s.ID), Except(actions...))(r)
+}
+
+// All RESTful actions in order of routing.
+var actions = []string{"index", "new", "create", "edit", "show", "update", "delete"}
+
+type resourceOptions struct {
+	id          middleware.IDKind
+	name        string // singular name of routes, like "user"
+	param       string // id param of nested resources
+	actions     map[string]bool
+	members     []extraRoute
+	collections []extraRoute
+	children    []child
+	routes      []func(r chi.Router, scope string)
+	shallow     bool
+}
+
+type extraRoute struct {
+	method, path string
+	handler      http.HandlerFunc
+}
+
+type child struct {
+	path string
+	rsc  IResource
+	opts []ResourceOption
+}
+
+// Option of Resource.
+type ResourceOption func(*resourceOptions)
+
+func newResourceOptions(rsc IResource, opts []ResourceOption) *resourceOptions {
+	o := &resourceOptions{actions: map[string]bool{}, name: nameOf(rsc)}
+	for _, a := range actions {
+		o.actions[a] = true
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.param == "" {
+		o.param = o.name + "_id"
+	}
+	return o
+}
+
+// Use id of kind in url, the id can be read by phoenix.IntID, phoenix.Int64ID
+// or phoenix.StringID according to kind.
+//
+//	r.Route("/posts", router.Resource(&PostController{}, router.WithID(middleware.Slug)))
+func WithID(kind middleware.IDKind) ResourceOption {
+	return func(o *resourceOptions) {
+		o.id = kind
+	}
+}
+
+// Route the actions only.
+func Only(actions ...string) ResourceOption {
+	return func(o *resourceOptions) {
+		for a := range o.actions {
+			o.actions[a] = false
+		}
+		for _, a := range actions {
+			o.actions[a] = true
+		}
+	}
+}
+
+// Route all actions except these.
+func Except(actions ...string) ResourceOption {
+	return func(o *resourceOptions) {
+		for _, a := range actions {
+			o.actions[a] = false
+		}
+	}
+}
+
+// Add a route on member, like POST /users/{id}/activate. The id is fetched
+// as the standard actions.
+func Member(method, path string, h http.HandlerFunc) ResourceOption {
+	return func(o *resourceOptions) {
+		o.members = append(o.members, extraRoute{method, path, h})
+	}
+}
+
+// Add a route on collection, like GET /users/search.
+func Collection(method, path string, h http.HandlerFunc) ResourceOption {
+	return func(o *resourceOptions) {
+		o.collections = append(o.collections, extraRoute{method, path, h})
+	}
+}
+
+// Singular name of the resource, default is derived from its type,
+// UserController is "user". Routes are named by it for URL:
+//
+//	users_path         GET /users, POST /users
+//	user_new_path      GET /users/new
+//	user_path          GET /users/{id}, PUT /users/{id}, ...
+//	user_edit_path     GET /users/{id}/edit
+//	user_activate_path member route activate
+//	users_search_path  collection route search
+//	user_posts_path    nested posts, GET /users/{user_id}/posts
+//	user_post_path     nested post, GET /users/{user_id}/posts/{id}
+//
+// Members of a shallow child are not prefixed, like post_path. When the same
+// resource is routed in several scopes, like /users and /admin/users, names
+// of the longer ones are prefixed by scope, like admin_users_path.
+func Name(name string) ResourceOption {
+	return func(o *resourceOptions) {
+		o.name = name
+	}
+}
+
+// Name of the id param which children see, it's fetched into context by the
+// same name. Default is the name with "_id", like "user_id".
+func Param(name string) ResourceOption {
+	return func(o *resourceOptions) {
+		o.param = name
+	}
+}
+
+// Nest a child resource at path under the member, like
+// /users/{user_id}/posts/{id}. The parent id is fetched into context by its
+// Param, then the child can read it:
+//
+//	r.Route("/users", router.Resource(&UserController{},
+//		router.Nested("posts", &PostController{}),
+//	))
+//
+//	userID := phoenix.NewKey[int]("user_id").MustGet(r.Context())
+func Nested(path string, rsc IResource, opts ...ResourceOption) ResourceOption {
+	if rsc == nil {
+		panic("resource can not be nil")
+	}
+	return func(o *resourceOptions) {
+		o.children = append(o.children, child{strings.Trim(path, "/"), rsc, opts})
+	}
+}
+
+// Nest routes set up by fn under the member, like /users/{user_id}/profile,
+// the parent id is fetched as Nested. scope is the name of the member, like
+// "user", to name routes in fn. It's for routers built on this one, like
+// plug.Resource.
+func NestedRoutes(fn func(r chi.Router, scope string)) ResourceOption {
+	return func(o *resourceOptions) {
+		o.routes = append(o.routes, fn)
+	}
+}
+
+// Make a nested resource shallow: only index, new, create and collection
+// routes are nested, members are routed at the top like /posts/{id}, since an
+// id is enough to find them.
+//
+// Route is the only entry point of shallow resources. Resource and
+// plug.Resource set up a sub router, which can not reach the top, so they
+// panic on a shallow child.
+func Shallow() ResourceOption {
+	return func(o *resourceOptions) {
+		o.shallow = true
+	}
+}
+
+// Route a full RESTful actions. It will panic when 'rsc' is nil, or a child
+// is Shallow, see Route for that.
+//
+// Usage:
+//
+//	r.Route("/users", router.Resource(&UserController{}))
+//	r.Route("/posts", router.Resource(&PostController{}, router.WithID(middleware.UUID), router.Except("new", "edit")))
+func Resource(rsc IResource, opts ...ResourceOption) func(chi.Router) {
+	if rsc == nil {
+		panic("resource can not be nil")
+	}
+	o := newResourceOptions(rsc, opts)
+	if o.hasShallow() {
+		panic("router: Shallow is only supported by router.Route")
+	}
+	return func(r chi.Router) {
+		o.mount(r, "", "", rsc, nil)
+	}
+}
+
+// Route resource rsc at path of r. Different from Resource, shallow children
+// can be routed at the top of r.
+//
+//	router.Route(r, "/users", &UserController{},
+//		router.Member(http.MethodPost, "activate", users.Activate),
+//		router.Collection(http.MethodGet, "search", users.Search),
+//		router.Nested("posts", &PostController{}, router.Shallow()),
+//	)
+//
+// Routes:
+//
+//	GET  /users/search
+//	POST /users/{id}/activate
+//	GET  /users/{user_id}/posts
+//	POST /users/{user_id}/posts
+//	GET  /posts/{id}
+//	...
+func Route(r chi.Router, path string, rsc IResource, opts ...ResourceOption) {
+	if rsc == nil {
+		panic("resource can not be nil")
+	}
+	prefix := strings.Trim(path, "/")
+	if prefix != "" {
+		prefix = "/" + prefix
+	}
+	newResourceOptions(rsc, opts).mount(r, prefix, "", rsc, r)
+}
+
+// Route RESTful action only 'actions'. It will panic when 'rsc' is nil.
+func ResourceOnly(rsc IResource, actions ...string) func(chi.Router) {
+	return Resource(rsc, Only(actions...))
+}
+
+// Route RESTful action expect 'actions'. It will panic when 'rsc' is nil.
+func ResourceExcept(rsc IResource, actions ...string) func(chi.Router) {
+	return Resource(rsc, Except(actions...))
+}
+
+// Mount all routes of rsc at prefix of r, shallow children are mounted at
+// root when it's not nil. Route names are prefixed by scope.
+func (o *resourceOptions) mount(r chi.Router, prefix, scope string, rsc IResource, root chi.Router) {
+	o.mountCollection(r, prefix, scope, rsc)
+	o.mountMember(r, prefix, scope, rsc, root)
+}
+
+// Whether any child, at any depth, is shallow.
+func (o *resourceOptions) hasShallow() bool {
+	for _, c := range o.children {
+		co := newResourceOptions(c.rsc, c.opts)
+		if co.shallow || co.hasShallow() {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *resourceOptions) mountCollection(r chi.Router, prefix, scope string, rsc IResource) {
+	index := prefix
+	if index == "" {
+		index = "/"
+	}
+	plural := scope + inflection.Plural(o.name)
+	for _, c := range o.collections {
+		path := strings.Trim(c.path, "/")
+		r.Method(c.method, prefix+"/"+path, namedHandler{name: plural + "_" + routeName(path) + "_path", auto: true, Handler: c.handler})
+	}
+	if o.actions["index"] {
+		r.Method(http.MethodGet, index, action(plural+"_path", rsc, "Index", rsc.Index)) // index: show a list of object
+	}
+	if o.actions["new"] {
+		r.Method(http.MethodGet, prefix+"/new", action(scope+o.name+"_new_path", rsc, "New", rsc.New)) // new: show create object form
+	}
+	if o.actions["create"] {
+		r.Method(http.MethodPost, index, action(plural+"_path", rsc, "Create", rsc.Create)) // create: save a new object
+	}
+}
+
+func (o *resourceOptions) mountMember(r chi.Router, prefix, scope string, rsc IResource, root chi.Router) {
+	member := prefix + "/{id}"
+	name := scope + o.name
+	mr := r.With(middleware.FetchIDOf(o.id, "id"))
+	if o.actions["edit"] {
+		mr.Method(http.MethodGet, member+"/edit", action(name+"_edit_path", rsc, "Edit", rsc.Edit)) // edit: show edit form
+	}
+	if o.actions["show"] {
+		mr.Method(http.MethodGet, member, action(name+"_path", rsc, "Show", rsc.Show)) // show: show one object detail by id
+	}
+	if o.actions["update"] {
+		mr.Method(http.MethodPatch, member, action(name+"_path", rsc, "Update", rsc.Update)) // update: save update object
+		mr.Method(http.MethodPut, member, action(name+"_path", rsc, "Update", rsc.Update))
+	}
+	if o.actions["delete"] {
+		mr.Method(http.MethodDelete, member, action(name+"_path", rsc, "Delete", rsc.Delete)) // delete: delete a object by id
+	}
+	for _, m := range o.members {
+		path := strings.Trim(m.path, "/")
+		mr.Method(m.method, member+"/"+path, namedHandler{name: name + "_" + routeName(path) + "_path", auto: true, Handler: m.handler})
+	}
+
+	cr := r.With(middleware.FetchIDOf(o.id, o.param))
+	for _, c := range o.children {
+		co := newResourceOptions(c.rsc, c.opts)
+		nested := prefix + "/{" + o.param + "}/" + c.path
+		if co.shallow {
+			co.mountCollection(cr, nested, name+"_", c.rsc)
+			co.mountMember(root, "/"+c.path, "", c.rsc, root)
+		} else {
+			co.mount(cr, nested, name+"_", c.rsc, root)
+		}
+	}
+	for _, fn := range o.routes {
+		// flattened instead of mounting at /{user_id}, which would take over
+		// /{id} of members
+		nested := chi.NewRouter()
+		fn(nested, name)
+		chi.Walk(nested, func(method, pattern string, h http.Handler, mws ...func(http.Handler) http.Handler) error {
+			cr.With(mws...).Method(method, prefix+"/{"+o.param+"}"+strings.TrimSuffix(pattern, "/"), h)
+			return nil
+		})
+	}
+}
+
+// Named handler of a RESTful action, it's shown as the type of rsc, like
+// "controllers.UserController.Show".
+func action(name string, rsc IResource, method string, h http.HandlerFunc) http.Handler {
+	return namedHandler{
+		name:    name,
+		handler: strings.TrimPrefix(fmt.Sprintf("%T.%s", underlying(rsc), method), "*"),
+		auto:    true,
+		Handler: h,
+	}
+}
+
+// Route name of path, "reset-password" is "reset_password".
+func routeName(path string) string {
+	return strings.NewReplacer("/", "_", "-", "_", "{", "", "}", "").Replace(path)
+}
+
+// Singular name of rsc, like "user" for UserController.
+func nameOf(rsc IResource) string {
+	return ResourceName(rsc)
+}
+
+// Singular name of a controller by its type, like "user" for UserController.
+// Resources are named by it unless Name is given.
+func ResourceName(controller any) string {
+	t := reflect.TypeOf(underlying(controller))
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	name := ""
+	if t != nil {
+		name = strings.TrimSuffix(t.Name(), "Controller")
+	}
+	if name == "" {
+		return "resource"
+	}
+	return inflection.Singular(snakecase.SnakeCase(name))
+}
+
+// The controller under rsc, Resources and adapters like plug.AsResource are
+// seen through, so they are named as the controller.
+func underlying(rsc any) any {
+	switch r := rsc.(type) {
+	case Resources:
+		return underlying(r.IResource)
+	case interface{ Controller() any }:
+		return underlying(r.Controller())
+	}
+	return rsc
+}
@@ -9,138 +9,43 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/DOVECYJ/phoenix/middleware"
 	"github.com/go-chi/chi/v5"
 )
 
-// A RESTful action interface
-type IResource interface {
-	Index(http.ResponseWriter, *http.Request)  // index: show a list of object
-	Edit(http.ResponseWriter, *http.Request)   // edit: show edit form
-	New(http.ResponseWriter, *http.Request)    // new: show create object form
-	Show(http.ResponseWriter, *http.Request)   // show: show one object detail by id
-	Create(http.ResponseWriter, *http.Request) // create: save a new object
-	Update(http.ResponseWriter, *http.Request) // update: save update object
-	Delete(http.ResponseWriter, *http.Request) // delete: delete a object by id
-}
-
-type Resources struct {
-	IResource
-}
-
-func (s Resources) Route(r chi.Router) {
-	Resource(s)(r)
-}
-
-func (s Resources) Only(r chi.Router, actions ...string) {
-	ResourceOnly(s, actions...)(r)
-}
-
-func (s Resources) Except(r chi.Router, actions ...string) {
-	ResourceExcept(s, actions...)(r)
-}
-
-// Route a full RESTful actions. It will panic when 'rsc' is nil.
-func Resource(rsc IResource) func(chi.Router) {
-	if rsc == nil {
-		panic("resource can not be nil")
-	}
-	return func(r chi.Router) {
-		r.Get("/", rsc.Index)   // index: show a list of object
-		r.Get("/new", rsc.New)  // new: show create object form
-		r.Post("/", rsc.Create) // create: save a new object
-		r.Route("/{id}", func(r chi.Router) {
-			r.Use(middleware.FetchID)
-			r.Get("/edit", rsc.Edit)  // edit: show edit form
-			r.Get("/", rsc.Show)      // show: show one object detail by id
-			r.Patch("/", rsc.Update)  // update: save update object
-			r.Put("/", rsc.Update)    // update: save update object
-			r.Delete("/", rsc.Delete) // delete: delete a object by id
-		})
-	}
-}
-
-// Route RESTful action only 'actions'. It will panic when 'rsc' is nil.
-func ResourceOnly(rsc IResource, actions ...string) func(chi.Router) {
-	if rsc == nil {
-		slog.Error("resource can not be nil")
-		panic("nil resource")
-	}
-	return func(r chi.Router) {
-		for _, action := range actions {
-			switch action {
-			case "index":
-				r.Get("/", rsc.Index)
-			case "edit":
-				r.With(middleware.FetchID).Get("/{id}/edit", rsc.Edit)
-			case "new":
-				r.Get("/new", rsc.New)
-			case "show":
-				r.With(middleware.FetchID).Get("/{id}", rsc.Show)
-			case "create":
-				r.Post("/", rsc.Create)
-			case "update":
-				r.With(middleware.FetchID).Patch("/{id}", rsc.Update)
-				r.With(middleware.FetchID).Put("/{id}", rsc.Update)
-			case "delete":
-				r.With(middleware.FetchID).Delete("/{id}", rsc.Delete)
-			}
+// Print routed path, versions of api are listed by their path prefix like
+// /api/v2/users. Pipelines of a route are printed when it has any.
+func PrintRouters(router chi.Router) {
+	for _, route := range Routes(router) {
+		args := []any{"name", route.Name, "handler", route.Handler}
+		if len(route.Pipelines) > 0 {
+			args = append(args, "pipelines", strings.Join(route.Pipelines, ","))
 		}
+		slog.Info(fmt.Sprintf("router: %-6s %s", route.Method, route.Pattern), args...)
 	}
 }
 
-// Route RESTful action expect 'actions'. It will panic when 'rsc' is nil.
-func ResourceExcept(rsc IResource, actions ...string) func(chi.Router) {
-	if rsc == nil {
-		slog.Error("resource can not be nil")
-		panic("nil resource")
-	}
-	allActions := map[string]struct{}{
-		"index":  {},
-		"edit":   {},
-		"new":    {},
-		"show":   {},
-		"create": {},
-		"update": {},
-		"delete": {},
-	}
-	for _, a := range actions {
-		delete(allActions, a)
-	}
-	if len(allActions) == 0 {
-		return func(r chi.Router) {}
-	}
-
-	only := make([]string, 0, len(allActions))
-	for k := range allActions {
-		only = append(only, k)
-	}
-	return ResourceOnly(rsc, only...)
-}
-
-// Print routed path.
-func PrintRouters(router chi.Router) {
-	chi.Walk(router,
-		func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
-			route = strings.Replace(route, "/*/", "/", -1)
-			slog.Info(fmt.Sprintf("router: %-6s %s", method, route))
-			return nil
-		},
-	)
-}
-
 // Serve static file on path in dir. Than means if you visit
 // localhost:8080/'path'/a.txt, file 'dir'/a.txt will be served.
 // And visit direacory will be forbidden.
+//
+// After phx digest, gzip and brotli variants are served by Accept-Encoding,
+// digested files are cached as immutable, and StaticPath resolves names to
+// them. StaticPath uses the path of the first ServeStatic.
 func ServeStatic(r chi.Router, path, dir string) {
 	if filepath.IsLocal(dir) {
 		workDir, _ := os.Getwd()
 		dir = filepath.Join(workDir, dir)
 	}
 	serveStatic(r, path, http.Dir(filepath.Clean(dir)), true)
+	staticMu.Lock()
+	if staticMount == "" {
+		staticMount = mountPath(path)
+	}
+	staticMu.Unlock()
 }
 
 // Serve static file on path in FS. This time visit directory will be fine.
+// Use StaticPathOf to link to its files.
 func ServeStaticFs(r chi.Router, path string, fs fs.FS) {
 	serveStatic(r, path, http.FS(fs), false)
 }
@@ -156,6 +61,7 @@ func serveStatic(r chi.Router, path string, root http.FileSystem, disableDir boo
 		r.Get(path, http.RedirectHandler(path+"/", http.StatusMovedPermanently).ServeHTTP)
 		path += "/"
 	}
+	m := loadManifest(path, root)
 	path += "*"
 
 	r.Get(path, func(w http.ResponseWriter, r *http.Request) {
@@ -163,6 +69,13 @@ func serveStatic(r chi.Router, path string, root http.FileSystem, disableDir boo
 			w.WriteHeader(http.StatusForbidden)
 			return
 		}
+		name := chi.URLParam(r, "*")
+		if _, ok := m.Digests[name]; ok {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		}
+		if name != "" && servePrecompressed(w, r, root, "/"+name) {
+			return
+		}
 		http.StripPrefix(
 			strings.TrimSuffix(chi.RouteContext(r.Context()).RoutePattern(), "/*"),
 			http.FileServer(root),
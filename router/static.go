@@ -0,0 +1,143 @@
+package router
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Name of the manifest written by phx digest in the static directory.
+const ManifestName = "cache_manifest.json"
+
+// Manifest of digested static files.
+type Manifest struct {
+	Version int               `json:"version"`
+	Latest  map[string]string `json:"latest"`  // logical path to digested path, like "css/app.css": "css/app-9f8e....css"
+	Digests map[string]Digest `json:"digests"` // digested path to its information
+}
+
+type Digest struct {
+	LogicalPath string `json:"logical_path"`
+	Digest      string `json:"digest"`
+	Size        int64  `json:"size"`
+	Mtime       int64  `json:"mtime"`
+}
+
+var (
+	staticMu    sync.RWMutex
+	staticMount string // path of the first ServeStatic
+	manifests   = map[string]*Manifest{}
+)
+
+// Path of static file name served by the first ServeStatic, see StaticPathOf.
+//
+//	<link rel="stylesheet" href={ router.StaticPath("css/app.css") }/>
+//
+// It's "/assets/css/app-9f8e....css" after digest, and "/assets/css/app.css"
+// before.
+func StaticPath(name string) string {
+	staticMu.RLock()
+	mount := staticMount
+	staticMu.RUnlock()
+	if mount == "" {
+		mount = "/"
+	}
+	return StaticPathOf(mount, name)
+}
+
+// Path of static file name served on mount by ServeStatic or ServeStaticFs,
+// it's resolved through the manifest written by phx digest in that directory,
+// so the digested file is used when there is one.
+//
+// mount is the path passed to ServeStatic, which is relative to the router it
+// is served on, so serve static files on the root router when pages link to
+// them.
+func StaticPathOf(mount, name string) string {
+	mount = mountPath(mount)
+	name = strings.TrimPrefix(name, "/")
+	staticMu.RLock()
+	m := manifests[mount]
+	staticMu.RUnlock()
+	if m != nil {
+		if digested, ok := m.Latest[name]; ok {
+			name = digested
+		}
+	}
+	return mount + name
+}
+
+// Path with a trailing slash.
+func mountPath(path string) string {
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return path
+}
+
+// Load manifest of root for mount, it's fine when there is no manifest.
+func loadManifest(mount string, root http.FileSystem) *Manifest {
+	m := &Manifest{}
+	if f, err := root.Open("/" + ManifestName); err == nil {
+		json.NewDecoder(f).Decode(m)
+		f.Close()
+	}
+	staticMu.Lock()
+	manifests[mount] = m
+	staticMu.Unlock()
+	return m
+}
+
+// Precompressed variants in order of preference.
+var encodings = []struct{ name, ext string }{{"br", ".br"}, {"gzip", ".gz"}}
+
+// Serve precompressed variant of name written by phx digest when the client
+// accepts it, returns false when there is none.
+func servePrecompressed(w http.ResponseWriter, r *http.Request, root http.FileSystem, name string) bool {
+	ctype := mime.TypeByExtension(path.Ext(name))
+	if ctype == "" {
+		return false
+	}
+	accept := r.Header.Get("Accept-Encoding")
+	for _, enc := range encodings {
+		if !acceptEncoding(accept, enc.name) {
+			continue
+		}
+		f, err := root.Open(name + enc.ext)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			continue
+		}
+		h := w.Header()
+		h.Set("Content-Type", ctype)
+		h.Set("Content-Encoding", enc.name)
+		h.Add("Vary", "Accept-Encoding")
+		http.ServeContent(w, r, name, info.ModTime(), f)
+		return true
+	}
+	return false
+}
+
+// Whether encoding is acceptable by Accept-Encoding header, q=0 means not.
+func acceptEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
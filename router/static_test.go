@@ -0,0 +1,51 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestStaticPath(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ManifestName), []byte(`{"latest":{"app.css":"app-abc.css"},"digests":{"app-abc.css":{}}}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "app-abc.css"), []byte("body{}"), 0o644)
+	fsys := fstest.MapFS{
+		ManifestName: {Data: []byte(`{"latest":{"app.js":"app-def.js"},"digests":{"app-def.js":{}}}`)},
+		"app-def.js": {Data: []byte("1")},
+		"app.css":    {Data: []byte("2")},
+	}
+	r := chi.NewRouter()
+	ServeStatic(r, "/assets", dir)
+	ServeStaticFs(r, "/vendor/", fsys)
+
+	tests := []struct{ got, want string }{
+		{StaticPath("app.css"), "/assets/app-abc.css"},
+		{StaticPath("/app.js"), "/assets/app.js"},
+		{StaticPathOf("/vendor", "app.js"), "/vendor/app-def.js"},
+		{StaticPathOf("/vendor/", "app.css"), "/vendor/app.css"},
+		{StaticPathOf("/none", "app.css"), "/none/app.css"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("path = %q, want %q", tt.got, tt.want)
+		}
+	}
+
+	cached := map[string]bool{"/assets/app-abc.css": true, "/vendor/app-def.js": true, "/vendor/app.css": false}
+	for path, immutable := range cached {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: code = %d", path, w.Code)
+		}
+		if got := w.Header().Get("Cache-Control") != ""; got != immutable {
+			t.Errorf("%s: cached = %v, want %v", path, got, immutable)
+		}
+	}
+}
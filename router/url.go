@@ -0,0 +1,346 @@
+package router
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/viper"
+)
+
+var (
+	routesMu sync.RWMutex
+	patterns = map[string]string{} // route name to pattern
+	required = map[string]bool{}   // route names checked by Load
+)
+
+func init() {
+	phoenix.BeforeLoadConfig("router", func() {
+		viper.SetDefault("http.url.scheme", "http")
+	})
+}
+
+// A named handler, its name is found by Load when walking routes.
+type namedHandler struct {
+	name    string
+	handler string // name of handler for display, empty means by Handler
+	auto    bool   // named by Resource, not by Named
+	http.Handler
+}
+
+// Give handler h a name, so its path can be built by URL.
+//
+//	r.Method(http.MethodGet, "/about", router.Named("about_path", page.About))
+//	router.URL("about_path") // "/about"
+//
+// Routes of Resource are named automatically, see Name.
+func Named(name string, h http.HandlerFunc) http.Handler {
+	return NamedHandler(name, h)
+}
+
+// Same to Named but for http.Handler, like the one made by Handle.
+func NamedHandler(name string, h http.Handler) http.Handler {
+	return namedHandler{name: name, Handler: h}
+}
+
+// Information of a route.
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Pattern     string   `json:"pattern"`
+	Name        string   `json:"name,omitempty"`
+	Handler     string   `json:"handler"`
+	Pipelines   []string `json:"pipelines"`   // names of pipelines in order
+	Middlewares []string `json:"middlewares"` // names of middlewares in order, with those of pipelines
+
+	// Types of request and response of a TypedHandler, nil for others.
+	Request  reflect.Type `json:"-"`
+	Response reflect.Type `json:"-"`
+	Status   int          `json:"-"` // status of successful response of a TypedHandler
+
+	auto bool // named by Resource
+}
+
+// All routes of r, sorted by pattern and method. Names of a resource routed
+// in several scopes are prefixed by scope, see Name.
+func Routes(r chi.Routes) []RouteInfo {
+	var routes []RouteInfo
+	chi.Walk(r, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		info := RouteInfo{Method: method, Pattern: cleanPattern(route), Pipelines: []string{}, Middlewares: []string{}}
+		info.addMiddlewares(middlewares)
+		if h, ok := handler.(namedHandler); ok {
+			info.Name, info.Handler, info.auto, handler = h.name, h.handler, h.auto, h.Handler
+		}
+		if th, ok := handler.(TypedHandler); ok {
+			info.Request, info.Response = th.Types()
+			info.Status = http.StatusOK
+			if s, ok := th.(interface{ Status() int }); ok {
+				info.Status = s.Status()
+			}
+		}
+		if info.Handler == "" {
+			info.Handler = handlerName(handler)
+		}
+		routes = append(routes, info)
+		return nil
+	})
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	scopeNames(routes)
+	return routes
+}
+
+// Rename routes of a resource routed in several scopes, like /users and
+// /admin/users. The one with the shortest pattern keeps the name, the others
+// are prefixed by their scope, like admin_users_path. Names given by Named
+// are kept as is.
+func scopeNames(routes []RouteInfo) {
+	patterns := map[string][]string{} // auto name to patterns
+	for _, route := range routes {
+		if !route.auto {
+			continue
+		}
+		ps := patterns[route.Name]
+		if len(ps) == 0 || ps[len(ps)-1] != route.Pattern {
+			patterns[route.Name] = append(ps, route.Pattern)
+		}
+	}
+	renames := map[[2]string]string{} // name and pattern to new name
+	for name, ps := range patterns {
+		if len(ps) < 2 {
+			continue
+		}
+		sort.SliceStable(ps, func(i, j int) bool {
+			return strings.Count(ps[i], "/") < strings.Count(ps[j], "/")
+		})
+		for _, p := range ps[1:] {
+			if scope := scopeOf(p, ps[0]); scope != "" {
+				renames[[2]string{name, p}] = scope + "_" + name
+			}
+		}
+	}
+	for i, route := range routes {
+		if name, ok := renames[[2]string{route.Name, route.Pattern}]; ok && route.auto {
+			routes[i].Name = name
+		}
+	}
+}
+
+// Scope of pattern compared with other, it's the static segments before their
+// common tail, like "admin" of /admin/users/{id} and /users/{id}.
+func scopeOf(pattern, other string) string {
+	segs, others := strings.Split(pattern, "/"), strings.Split(other, "/")
+	for len(segs) > 0 && len(others) > 0 && segs[len(segs)-1] == others[len(others)-1] {
+		segs, others = segs[:len(segs)-1], others[:len(others)-1]
+	}
+	var scope []string
+	for _, seg := range segs {
+		if seg != "" && !strings.HasPrefix(seg, "{") {
+			scope = append(scope, routeName(seg))
+		}
+	}
+	return strings.Join(scope, "_")
+}
+
+// Add middlewares in order, pipelines are expanded.
+func (info *RouteInfo) addMiddlewares(mws []func(http.Handler) http.Handler) {
+	for _, mw := range mws {
+		if p := pipelineOf(mw); p != nil {
+			info.Pipelines = append(info.Pipelines, p.name)
+			info.addMiddlewares(p.mws)
+			continue
+		}
+		info.Middlewares = append(info.Middlewares, middlewareName(mw))
+	}
+}
+
+// Declare route names that URL is called with, Load returns error when any of
+// them is not routed. So a typo is found at startup, instead of a panic of URL
+// at request time. Generated controllers require the names they use:
+//
+//	func init() {
+//		router.Require("users_path", "user_path")
+//	}
+func Require(names ...string) {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	for _, name := range names {
+		required[name] = true
+	}
+}
+
+// Load names of routes in r for URL, call it after all routes are set up. It
+// returns error when a name given by Named is used by different patterns, or
+// a name declared by Require is not found. Names of resources routed in
+// several scopes are prefixed by scope, like admin_users_path, if they still
+// conflict, the first one is used.
+func Load(r chi.Routes) error {
+	names := map[string]string{}
+	autos := map[string]bool{}
+	for _, route := range Routes(r) {
+		if route.Name == "" {
+			continue
+		}
+		if p, ok := names[route.Name]; ok && p != route.Pattern {
+			if !route.auto || !autos[route.Name] {
+				return fmt.Errorf("route name %s is used by %s and %s", route.Name, p, route.Pattern)
+			}
+			slog.Warn("route name is used by another pattern", "name", route.Name, "pattern", route.Pattern, "used", p)
+			continue
+		}
+		names[route.Name] = route.Pattern
+		autos[route.Name] = route.auto
+	}
+	routesMu.Lock()
+	defer routesMu.Unlock()
+	var missing []string
+	for name := range required {
+		if _, ok := names[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("router: required routes %s are not found", strings.Join(missing, ", "))
+	}
+	patterns = names
+	return nil
+}
+
+// Build path of the route name, params fill url params in order. A trailing
+// url.Values param is encoded as query string. It panics when the name is not
+// found or params do not match, like a wrong function call, declare the name
+// by Require so Load finds it at startup.
+//
+// Usage:
+//
+//	router.URL("users_path")                                  // "/users"
+//	router.URL("user_path", 1)                                // "/users/1"
+//	router.URL("user_posts_path", 1, url.Values{"page": {"2"}}) // "/users/1/posts?page=2"
+//
+// In templ components:
+//
+//	<a href={ templ.URL(router.URL("user_edit_path", user.ID)) }>Edit</a>
+func URL(name string, params ...any) string {
+	path, err := BuildPath(name, params...)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+// Build full url of route name with the configured scheme and host, see URL.
+//
+//	[http.url]
+//	scheme = 'https'
+//	host = 'example.com'
+func AbsoluteURL(name string, params ...any) string {
+	return BaseURL() + URL(name, params...)
+}
+
+// Scheme and host of full urls. The host defaults to localhost with port of
+// http.addr.
+func BaseURL() string {
+	host := viper.GetString("http.url.host")
+	if host == "" {
+		host = "localhost"
+		if _, port, err := net.SplitHostPort(viper.GetString("http.addr")); err == nil && port != "" {
+			host += ":" + port
+		}
+	}
+	return viper.GetString("http.url.scheme") + "://" + host
+}
+
+// Same to URL but returns error.
+func BuildPath(name string, params ...any) (string, error) {
+	routesMu.RLock()
+	pattern, ok := patterns[name]
+	routesMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("router: route %s not found, is router.Load called?", name)
+	}
+	var query url.Values
+	if n := len(params); n > 0 {
+		if q, ok := params[n-1].(url.Values); ok {
+			query, params = q, params[:n-1]
+		}
+	}
+	var sb strings.Builder
+	rest, i := pattern, 0
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			break
+		}
+		if i >= len(params) {
+			return "", fmt.Errorf("router: route %s %s needs more params", name, pattern)
+		}
+		sb.WriteString(rest[:start])
+		sb.WriteString(url.PathEscape(fmt.Sprint(params[i])))
+		rest, i = rest[start+end+1:], i+1
+	}
+	if i != len(params) {
+		return "", fmt.Errorf("router: route %s %s has %d params, got %d", name, pattern, i, len(params))
+	}
+	sb.WriteString(rest)
+	if len(query) > 0 {
+		sb.WriteString("?" + query.Encode())
+	}
+	return sb.String(), nil
+}
+
+// Remove trailing slash of sub router index, "/users/" is "/users".
+func cleanPattern(pattern string) string {
+	if len(pattern) > 1 {
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	return pattern
+}
+
+// Name of handler for display, like "controllers.UserController.Show".
+func handlerName(h http.Handler) string {
+	if s, ok := h.(fmt.Stringer); ok {
+		return s.String()
+	}
+	if f, ok := h.(http.HandlerFunc); ok {
+		if name := funcName(f); name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("%T", h)
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// Name of middleware mw, closures are named by their maker, like
+// "middleware.Timeout".
+func middlewareName(mw func(http.Handler) http.Handler) string {
+	return closureSuffix.ReplaceAllString(funcName(mw), "")
+}
+
+// Name of function fn without package path.
+func funcName(fn any) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return ""
+	}
+	name := strings.TrimSuffix(f.Name(), "-fm")
+	return name[strings.LastIndexByte(name, '/')+1:]
+}
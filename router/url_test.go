@@ -0,0 +1,102 @@
+package router
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type UserController struct{}
+
+func (UserController) Index(w http.ResponseWriter, r *http.Request)  {}
+func (UserController) Edit(w http.ResponseWriter, r *http.Request)   {}
+func (UserController) New(w http.ResponseWriter, r *http.Request)    {}
+func (UserController) Show(w http.ResponseWriter, r *http.Request)   {}
+func (UserController) Create(w http.ResponseWriter, r *http.Request) {}
+func (UserController) Update(w http.ResponseWriter, r *http.Request) {}
+func (UserController) Delete(w http.ResponseWriter, r *http.Request) {}
+
+func TestLoadScopes(t *testing.T) {
+	r := chi.NewRouter()
+	r.Route("/users", Resource(UserController{}))
+	r.Route("/admin/users", Resource(UserController{}))
+	r.Route("/api/v1/users", Resource(UserController{}))
+	if err := Load(r); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name   string
+		params []any
+		want   string
+	}{
+		{"users_path", nil, "/users"},
+		{"user_path", []any{1}, "/users/1"},
+		{"admin_users_path", nil, "/admin/users"},
+		{"admin_user_edit_path", []any{1}, "/admin/users/1/edit"},
+		{"api_v1_user_path", []any{1}, "/api/v1/users/1"},
+		{"api_v1_user_new_path", nil, "/api/v1/users/new"},
+	}
+	for _, c := range cases {
+		if got := URL(c.name, c.params...); got != c.want {
+			t.Errorf("URL(%s) = %s, want %s", c.name, got, c.want)
+		}
+	}
+
+	r = chi.NewRouter()
+	page := func(w http.ResponseWriter, r *http.Request) {}
+	r.Method(http.MethodGet, "/a", Named("page_path", page))
+	r.Method(http.MethodGet, "/b", Named("page_path", page))
+	if err := Load(r); err == nil {
+		t.Error("want error of names given by Named")
+	}
+}
+
+func TestRouteShallow(t *testing.T) {
+	r := chi.NewRouter()
+	Route(r, "/users", UserController{}, Nested("posts", UserController{}, Name("post"), Shallow()))
+	var patterns []string
+	chi.Walk(r, func(method, pattern string, h http.Handler, mws ...func(http.Handler) http.Handler) error {
+		patterns = append(patterns, method+" "+pattern)
+		return nil
+	})
+	for _, want := range []string{"GET /users/{user_id}/posts", "GET /users/{user_id}/posts/new", "GET /posts/{id}", "DELETE /posts/{id}"} {
+		if !slices.Contains(patterns, want) {
+			t.Errorf("%s is not routed", want)
+		}
+	}
+	if slices.Contains(patterns, "GET /users/{user_id}/posts/{id}") {
+		t.Error("member of shallow child is nested")
+	}
+}
+
+func TestResourceShallow(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Resource with a shallow child should panic")
+		}
+	}()
+	Resource(UserController{}, Nested("posts", UserController{}, Nested("comments", UserController{}, Shallow())))
+}
+
+func TestLoadRequired(t *testing.T) {
+	defer func() {
+		required = map[string]bool{}
+	}()
+	r := chi.NewRouter()
+	r.Route("/users", Resource(UserController{}))
+	Require("users_path", "user_path")
+	if err := Load(r); err != nil {
+		t.Fatal(err)
+	}
+	Require("user_path", "usres_path", "post_path")
+	err := Load(r)
+	if err == nil || !strings.Contains(err.Error(), "post_path, usres_path") {
+		t.Fatalf("err = %v", err)
+	}
+	if got := URL("user_path", 1); got != "/users/1" {
+		t.Errorf("failed Load changed routes, user_path = %s", got)
+	}
+}
@@ -0,0 +1,278 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DOVECYJ/phoenix"
+	"github.com/DOVECYJ/phoenix/render"
+	"github.com/go-chi/chi/v5"
+)
+
+var ErrUnsupportedVersion = errors.New("unsupported api version")
+
+// Version of api requested by client, it's set by Versions.
+var VersionKey = phoenix.NewKey[int]("api_version")
+
+func init() {
+	phoenix.RegisterError(ErrUnsupportedVersion, http.StatusNotFound, 0, "")
+}
+
+// Versions route requests to versions of api side by side. The version is
+// selected by path prefix, Accept media type or header, in that order:
+//
+//	GET /api/v2/users
+//	GET /api/users  Accept: application/vnd.blog.v2+json
+//	GET /api/users  API-Version: 2
+//
+// The latest version is used when a request has none. A route not defined in
+// a version falls back to the nearest lower version which has it, so a new
+// version only routes what it changes.
+//
+// Usage:
+//
+//	api := router.NewVersions(router.VendorMedia("blog"))
+//	api.Version(1, func(r chi.Router) {
+//		r.Get("/users", v1.ListUsers)
+//		r.Get("/posts", v1.ListPosts)
+//	}, router.Deprecated(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), "https://example.com/docs/v2"),
+//		router.Sunset(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+//	api.Version(2, func(r chi.Router) {
+//		r.Get("/users", v2.ListUsers) // GET /api/v2/posts is v1.ListPosts
+//	})
+//	root.Mount("/api", api)
+//
+// Routes are listed per version with fallbacks, like /api/v2/posts, and their
+// names are prefixed by version, like "v2_users_path".
+type Versions struct {
+	versions []*version // in ascending order
+	vendor   string
+	header   string
+	def      int
+	media    *regexp.Regexp
+}
+
+type version struct {
+	n          int
+	router     chi.Router
+	deprecated time.Time
+	link       string
+	sunset     time.Time
+}
+
+// Option of Versions.
+type VersionsOption func(*Versions)
+
+// Option of a version.
+type VersionOption func(*version)
+
+// Select version by Accept media type application/vnd.{vendor}.v{n}+json.
+// Without it any vendor is accepted.
+func VendorMedia(vendor string) VersionsOption {
+	return func(vs *Versions) {
+		vs.vendor = regexp.QuoteMeta(vendor)
+	}
+}
+
+// Select version by header name, default is API-Version. The served version is
+// also set in the response header.
+func VersionHeader(name string) VersionsOption {
+	return func(vs *Versions) {
+		vs.header = name
+	}
+}
+
+// Version used when a request has none, default is the latest.
+func DefaultVersion(n int) VersionsOption {
+	return func(vs *Versions) {
+		vs.def = n
+	}
+}
+
+// Mark a version deprecated since the time, link is the document of migration
+// and can be empty. Responses of it have Deprecation and Link headers.
+func Deprecated(since time.Time, link string) VersionOption {
+	return func(v *version) {
+		v.deprecated = since
+		v.link = link
+	}
+}
+
+// Set the time a version will be removed, responses of it have Sunset header.
+func Sunset(at time.Time) VersionOption {
+	return func(v *version) {
+		v.sunset = at
+	}
+}
+
+// Create versions, add them by Version.
+func NewVersions(opts ...VersionsOption) *Versions {
+	vs := &Versions{header: "API-Version", vendor: `[\w.-]+?`}
+	for _, opt := range opts {
+		opt(vs)
+	}
+	vs.media = regexp.MustCompile(`(?i)application/vnd\.` + vs.vendor + `\.v(\d+)\b`)
+	return vs
+}
+
+// Add version n, routes are set up by fn. It panics when n is not positive or
+// it is added already.
+func (vs *Versions) Version(n int, fn func(r chi.Router), opts ...VersionOption) {
+	if n < 1 {
+		panic(fmt.Sprintf("router: invalid api version %d", n))
+	}
+	if vs.exact(n) != nil {
+		panic(fmt.Sprintf("router: api version %d is added already", n))
+	}
+	v := &version{n: n, router: chi.NewRouter()}
+	for _, opt := range opts {
+		opt(v)
+	}
+	fn(v.router)
+	vs.versions = append(vs.versions, v)
+	sort.Slice(vs.versions, func(i, j int) bool { return vs.versions[i].n < vs.versions[j].n })
+}
+
+func (vs *Versions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		rctx = chi.NewRouteContext()
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+	}
+	path := rctx.RoutePath
+	if path == "" {
+		path = r.URL.Path
+	}
+	n, path := vs.requested(r, path)
+	requested, served := vs.nearest(n), vs.lookup(n, r.Method, path)
+	if requested == nil || served == nil {
+		render.Error(w, r, ErrUnsupportedVersion)
+		return
+	}
+
+	h := w.Header()
+	h.Add("Vary", "Accept")
+	h.Add("Vary", vs.header)
+	h.Set(vs.header, strconv.Itoa(requested.n))
+	if !requested.deprecated.IsZero() {
+		h.Set("Deprecation", "@"+strconv.FormatInt(requested.deprecated.Unix(), 10))
+		if requested.link != "" {
+			h.Add("Link", `<`+requested.link+`>; rel="deprecation"; type="text/html"`)
+		}
+	}
+	if !requested.sunset.IsZero() {
+		h.Set("Sunset", requested.sunset.UTC().Format(http.TimeFormat))
+	}
+
+	rctx.RoutePath = path
+	served.router.ServeHTTP(w, r.WithContext(VersionKey.With(r.Context(), requested.n)))
+}
+
+var pathVersion = regexp.MustCompile(`^/v(\d+)(/.*)?$`)
+
+// Version requested by r and the path without version prefix.
+func (vs *Versions) requested(r *http.Request, path string) (int, string) {
+	if m := pathVersion.FindStringSubmatch(path); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if m[2] == "" {
+			m[2] = "/"
+		}
+		return n, m[2]
+	}
+	if m := vs.media.FindStringSubmatch(r.Header.Get("Accept")); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n, path
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(r.Header.Get(vs.header)), "v")); err == nil {
+		return n, path
+	}
+	if vs.def > 0 {
+		return vs.def, path
+	}
+	if len(vs.versions) > 0 {
+		return vs.versions[len(vs.versions)-1].n, path
+	}
+	return 0, path
+}
+
+func (vs *Versions) exact(n int) *version {
+	for _, v := range vs.versions {
+		if v.n == n {
+			return v
+		}
+	}
+	return nil
+}
+
+// The highest version not greater than n.
+func (vs *Versions) nearest(n int) *version {
+	for i := len(vs.versions) - 1; i >= 0; i-- {
+		if vs.versions[i].n <= n {
+			return vs.versions[i]
+		}
+	}
+	return nil
+}
+
+// The highest version not greater than n which has the route, or the nearest
+// one so it can respond 404 or 405.
+func (vs *Versions) lookup(n int, method, path string) *version {
+	for i := len(vs.versions) - 1; i >= 0; i-- {
+		v := vs.versions[i]
+		if v.n <= n && v.router.Match(chi.NewRouteContext(), method, path) {
+			return v
+		}
+	}
+	return vs.nearest(n)
+}
+
+// Routes of each version with fallbacks, like "/v2/users".
+func (vs *Versions) Routes() []chi.Route {
+	var routes []chi.Route
+	for i, v := range vs.versions {
+		handlers := map[string]map[string]http.Handler{} // pattern to method to handler
+		var patterns []string
+		for j := i; j >= 0; j-- {
+			prefix := "v" + strconv.Itoa(v.n) + "_"
+			chi.Walk(vs.versions[j].router, func(method, pattern string, h http.Handler, mws ...func(http.Handler) http.Handler) error {
+				if handlers[pattern] == nil {
+					handlers[pattern] = map[string]http.Handler{}
+					patterns = append(patterns, pattern)
+				}
+				if _, ok := handlers[pattern][method]; ok {
+					return nil // overridden by the higher version
+				}
+				if nh, ok := h.(namedHandler); ok {
+					nh.name = prefix + nh.name
+					h = nh
+				}
+				handlers[pattern][method] = chi.Chain(mws...).Handler(h)
+				return nil
+			})
+		}
+		sort.Strings(patterns)
+		for _, pattern := range patterns {
+			routes = append(routes, chi.Route{Pattern: "/v" + strconv.Itoa(v.n) + pattern, Handlers: handlers[pattern]})
+		}
+	}
+	return routes
+}
+
+// Middlewares are in each version.
+func (vs *Versions) Middlewares() chi.Middlewares {
+	return nil
+}
+
+// Match path with version prefix, or of the default version.
+func (vs *Versions) Match(rctx *chi.Context, method, path string) bool {
+	n, path := vs.requested(&http.Request{Header: http.Header{}}, path)
+	v := vs.lookup(n, method, path)
+	return v != nil && v.router.Match(rctx, method, path)
+}
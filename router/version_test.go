@@ -0,0 +1,156 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func versioned(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s requested v%d", name, VersionKey.MustGet(r.Context()))
+	}
+}
+
+func newVersions(opts ...VersionsOption) *Versions {
+	api := NewVersions(opts...)
+	api.Version(2, func(r chi.Router) {
+		r.Method(http.MethodGet, "/users", Named("users_path", versioned("v2 users")))
+	})
+	api.Version(1, func(r chi.Router) {
+		r.Method(http.MethodGet, "/users", Named("users_path", versioned("v1 users")))
+		r.Method(http.MethodGet, "/posts", Named("posts_path", versioned("v1 posts")))
+	}, Deprecated(time.Unix(1717200000, 0), "https://example.com/v2"), Sunset(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	api.Version(3, func(r chi.Router) {
+		r.Method(http.MethodGet, "/comments", Named("comments_path", versioned("v3 comments")))
+	})
+	return api
+}
+
+func TestVersions(t *testing.T) {
+	root := chi.NewRouter()
+	root.Mount("/api", newVersions(VendorMedia("blog")))
+	defRoot := chi.NewRouter()
+	defRoot.Mount("/api", newVersions(DefaultVersion(1), VersionHeader("X-Version")))
+
+	cases := []struct {
+		name   string
+		root   http.Handler
+		path   string
+		header map[string]string
+		code   int
+		body   string
+	}{
+		{"path", root, "/api/v2/users", nil, 200, "v2 users requested v2"},
+		{"path fallback", root, "/api/v2/posts", nil, 200, "v1 posts requested v2"},
+		{"fallback over versions", root, "/api/v3/posts", nil, 200, "v1 posts requested v3"},
+		{"higher than latest", root, "/api/v9/users", nil, 200, "v2 users requested v3"},
+		{"media", root, "/api/users", map[string]string{"Accept": "application/vnd.blog.v1+json"}, 200, "v1 users requested v1"},
+		{"other vendor", root, "/api/users", map[string]string{"Accept": "application/vnd.other.v1+json"}, 200, "v2 users requested v3"},
+		{"header", root, "/api/users", map[string]string{"API-Version": "v1"}, 200, "v1 users requested v1"},
+		{"path over header", root, "/api/v2/users", map[string]string{"API-Version": "1"}, 200, "v2 users requested v2"},
+		{"latest", root, "/api/users", nil, 200, "v2 users requested v3"},
+		{"default", defRoot, "/api/users", nil, 200, "v1 users requested v1"},
+		{"custom header", defRoot, "/api/users", map[string]string{"X-Version": "2"}, 200, "v2 users requested v2"},
+		{"below lowest", root, "/api/v0/users", nil, 404, ""},
+		{"below lowest by header", root, "/api/users", map[string]string{"API-Version": "0"}, 404, ""},
+		{"no route", root, "/api/v2/comments", nil, 404, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, c.path, nil)
+			for k, v := range c.header {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			c.root.ServeHTTP(w, r)
+			if w.Code != c.code {
+				t.Fatalf("code = %d, want %d", w.Code, c.code)
+			}
+			if c.body != "" && w.Body.String() != c.body {
+				t.Errorf("body = %q, want %q", w.Body, c.body)
+			}
+		})
+	}
+}
+
+func TestVersionHeaders(t *testing.T) {
+	api := newVersions()
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/users", nil))
+	h := w.Header()
+	if got := h.Get("API-Version"); got != "1" {
+		t.Errorf("API-Version = %q", got)
+	}
+	if got := h.Get("Deprecation"); got != "@"+strconv.Itoa(1717200000) {
+		t.Errorf("Deprecation = %q", got)
+	}
+	if got := h.Get("Link"); got != `<https://example.com/v2>; rel="deprecation"; type="text/html"` {
+		t.Errorf("Link = %q", got)
+	}
+	if got := h.Get("Sunset"); got != "Wed, 01 Jan 2025 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", got)
+	}
+
+	// the requested version decides, not the one serving the fallback
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/posts", nil))
+	if h := w.Header(); h.Get("API-Version") != "2" || h.Get("Deprecation") != "" || h.Get("Sunset") != "" {
+		t.Errorf("v2 headers: %v", h)
+	}
+}
+
+func TestVersionRoutes(t *testing.T) {
+	root := chi.NewRouter()
+	root.Mount("/api", newVersions())
+	names := map[string]string{}
+	for _, route := range Routes(root) {
+		names[route.Pattern] = route.Name
+	}
+	want := map[string]string{
+		"/api/v1/users":    "v1_users_path",
+		"/api/v1/posts":    "v1_posts_path",
+		"/api/v2/users":    "v2_users_path",
+		"/api/v2/posts":    "v2_posts_path",
+		"/api/v3/users":    "v3_users_path",
+		"/api/v3/comments": "v3_comments_path",
+	}
+	for pattern, name := range want {
+		if names[pattern] != name {
+			t.Errorf("%s is named %q, want %q", pattern, names[pattern], name)
+		}
+	}
+	if _, ok := names["/api/v2/comments"]; ok {
+		t.Error("v2 has route of v3")
+	}
+}
+
+func TestVersionMatch(t *testing.T) {
+	api := newVersions()
+	cases := map[string]bool{
+		"/v2/posts":    true,
+		"/v1/users":    true,
+		"/users":       true,
+		"/comments":    true,
+		"/v1/comments": false,
+		"/v0/users":    false,
+		"/v2/nothing":  false,
+	}
+	for path, want := range cases {
+		if got := api.Match(chi.NewRouteContext(), http.MethodGet, path); got != want {
+			t.Errorf("Match(%s) = %v, want %v", path, got, want)
+		}
+	}
+
+	// chi finds the mounted versions by Match too
+	root := chi.NewRouter()
+	root.Mount("/api", api)
+	if !root.Match(chi.NewRouteContext(), http.MethodGet, "/api/v2/posts") {
+		t.Error("root does not match /api/v2/posts")
+	}
+}
@@ -0,0 +1,128 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Storage is the destination of uploaded files. Save reads r until EOF and
+// returns the location of the file. When r returns an error, Save should
+// return it and must not keep a partial file if it can.
+type Storage interface {
+	Save(ctx context.Context, name string, r io.Reader) (location string, err error)
+}
+
+// StorageFunc adapts a function to Storage.
+type StorageFunc func(ctx context.Context, name string, r io.Reader) (string, error)
+
+func (f StorageFunc) Save(ctx context.Context, name string, r io.Reader) (string, error) {
+	return f(ctx, name, r)
+}
+
+// Save files into local directory dir, the location is the file path. The
+// name may contain sub directories but must not escape dir. An
+// existing file is not replaced and os.ErrExist is returned, unless replace
+// is true.
+func Dir(dir string, replace ...bool) Storage {
+	overwrite := len(replace) > 0 && replace[0]
+	return StorageFunc(func(ctx context.Context, name string, r io.Reader) (string, error) {
+		if !filepath.IsLocal(name) {
+			return "", ErrInvalidFilename
+		}
+		dest := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+			return "", err
+		}
+		if !overwrite && exist(dest) {
+			return "", os.ErrExist
+		}
+		// write into a temporary file, so a failed upload leaves nothing
+		tmp, err := os.CreateTemp(filepath.Dir(dest), ".upload-*")
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(tmp.Name())
+		if _, err = io.Copy(tmp, r); err != nil {
+			tmp.Close()
+			return "", err
+		}
+		if err = tmp.Close(); err != nil {
+			return "", err
+		}
+		if !overwrite {
+			// link fails if dest is created meanwhile
+			if err = os.Link(tmp.Name(), dest); err == nil {
+				return dest, nil
+			}
+			if errors.Is(err, os.ErrExist) {
+				return "", err
+			}
+			// no hard link, like across devices, still never replace dest
+			if err = copyExcl(tmp.Name(), dest); err != nil {
+				return "", err
+			}
+			return dest, nil
+		}
+		return dest, os.Rename(tmp.Name(), dest)
+	})
+}
+
+// Copy src into dest, which must not exist. A partial dest is removed.
+func copyExcl(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return err
+	}
+	if err = out.Close(); err != nil {
+		os.Remove(dest)
+	}
+	return err
+}
+
+// Writer of an object, a subset of oss.OssWriter. Importing oss here would
+// link all its vendor SDKs into every app which uploads to local disk.
+type ObjectWriter interface {
+	Write(io.Reader) error
+}
+
+// Save files into oss, open returns the writer of file name. The location is
+// the name.
+//
+// Usage:
+//
+//	upload.OSS(func(name string) (upload.ObjectWriter, error) {
+//		return oss.Open("ali", "uploads/"+name, false)
+//	})
+func OSS(open func(name string) (ObjectWriter, error)) Storage {
+	return StorageFunc(func(ctx context.Context, name string, r io.Reader) (string, error) {
+		w, err := open(name)
+		if err != nil {
+			return "", err
+		}
+		if err = w.Write(r); err != nil {
+			return "", err
+		}
+		if c, ok := w.(interface{ Url() string }); ok {
+			return c.Url(), nil
+		}
+		return name, nil
+	})
+}
+
+func exist(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,310 @@
+// Package upload receive multipart files by streaming, the body is never
+// buffered into memory or temporary files as a whole.
+//
+// Usage:
+//
+//	uploader := upload.New(upload.Dir("priv/uploads"), upload.Options{
+//		MaxFileSize: 10 << 20,
+//		MaxFiles:    5,
+//		Allowed:     []string{"image/*", "application/pdf"},
+//	})
+//
+//	func (c *AlbumController) Upload(w http.ResponseWriter, r *http.Request) {
+//		res, err := uploader.Receive(r)
+//		if err != nil {
+//			render.Error(w, r, err)
+//			return
+//		}
+//		for _, f := range res.Files["photos"] {
+//			...
+//		}
+//	}
+package upload
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/DOVECYJ/phoenix"
+)
+
+const (
+	defaultMaxFileSize = 32 << 20 // 32M
+	defaultMaxFiles    = 10
+	maxValueSize       = 1 << 20 // max size of a non-file form value
+	sniffLen           = 512
+)
+
+var (
+	ErrFileTooLarge    = errors.New("file too large")
+	ErrBodyTooLarge    = errors.New("request body too large")
+	ErrTooManyFiles    = errors.New("too many files")
+	ErrTypeNotAllowed  = errors.New("file type not allowed")
+	ErrNotMultipart    = errors.New("request is not multipart")
+	ErrBodyParsed      = errors.New("request body is already parsed")
+	ErrInvalidFilename = errors.New("invalid file name")
+)
+
+func init() {
+	phoenix.RegisterError(ErrFileTooLarge, http.StatusRequestEntityTooLarge, 0, "")
+	phoenix.RegisterError(ErrBodyTooLarge, http.StatusRequestEntityTooLarge, 0, "")
+	phoenix.RegisterError(ErrTooManyFiles, http.StatusRequestEntityTooLarge, 0, "")
+	phoenix.RegisterError(ErrTypeNotAllowed, http.StatusUnsupportedMediaType, 0, "")
+	phoenix.RegisterError(ErrNotMultipart, http.StatusBadRequest, 0, "")
+	phoenix.RegisterError(ErrBodyParsed, http.StatusInternalServerError, 0, "")
+	phoenix.RegisterError(ErrInvalidFilename, http.StatusBadRequest, 0, "")
+}
+
+// Limits and checks of an Uploader, zero value use the defaults.
+type Options struct {
+	MaxFileSize  int64    // max size of each file, default 32M
+	MaxTotalSize int64    // max size of all files and values, default no limit
+	MaxFiles     int      // max count of files, default 10
+	Fields       []string // accepted file fields, default all
+	// Allowed MIME types detected by content, like "image/png" or "image/*".
+	// Default all types are allowed.
+	Allowed []string
+	// Name the stored file, default is the sanitized file name. Return a
+	// unique name like uuid to avoid conflicts.
+	Name func(f *File) string
+}
+
+// Metadata of a received file.
+type File struct {
+	Field       string `json:"field"`        // form field name
+	Filename    string `json:"filename"`     // sanitized file name from client
+	Name        string `json:"name"`         // name in storage
+	Location    string `json:"location"`     // location returned by storage
+	Size        int64  `json:"size"`         // size in bytes
+	ContentType string `json:"content_type"` // detected by content
+	SHA256      string `json:"sha256"`       // hex encoded
+}
+
+// Result of Receive, files are grouped by field in order of the request.
+type Result struct {
+	Files  map[string][]File
+	Values url.Values // non-file form values
+}
+
+// Get the first file of field.
+func (r *Result) File(field string) (File, bool) {
+	if fs := r.Files[field]; len(fs) > 0 {
+		return fs[0], true
+	}
+	return File{}, false
+}
+
+// Uploader receive files into a Storage.
+type Uploader struct {
+	storage Storage
+	opts    Options
+}
+
+// Create an Uploader which saves files into storage.
+func New(storage Storage, opts Options) *Uploader {
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = defaultMaxFileSize
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = defaultMaxFiles
+	}
+	if opts.Name == nil {
+		opts.Name = func(f *File) string { return f.Filename }
+	}
+	return &Uploader{storage: storage, opts: opts}
+}
+
+// Receive all files of r. Files are streamed into storage one by one, and
+// checked while streaming, the SHA-256 is computed at the same time. Parts of
+// fields not in Options.Fields are discarded.
+//
+// When an error occurs, files already saved are kept in storage and returned
+// with the error, so caller can clean them up.
+//
+// The body must not be read before, by ParseMultipartForm, FormValue or
+// PostFormValue in a handler or middleware, otherwise it returns
+// ErrBodyParsed. middleware.CSRF and middleware.MethodSpoofing only peek
+// the leading fields, so they work before it.
+func (u *Uploader) Receive(r *http.Request) (*Result, error) {
+	if r.MultipartForm != nil {
+		return nil, fmt.Errorf("%w by ParseMultipartForm, FormValue or PostFormValue", ErrBodyParsed)
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotMultipart, err)
+	}
+	res := &Result{Files: map[string][]File{}, Values: url.Values{}}
+	var total, count int64
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		err = u.receivePart(r.Context(), part, res, &total, &count)
+		part.Close()
+		if err != nil {
+			return res, err
+		}
+	}
+}
+
+func (u *Uploader) receivePart(ctx context.Context, part *multipart.Part, res *Result, total, count *int64) error {
+	field := part.FormName()
+	if field == "" {
+		return nil
+	}
+	if part.FileName() == "" {
+		// a plain value
+		value, err := io.ReadAll(u.limit(part, maxValueSize, total, ErrBodyTooLarge))
+		if err != nil {
+			return err
+		}
+		res.Values.Add(field, string(value))
+		return nil
+	}
+	if !u.accept(field) {
+		return nil
+	}
+	if *count++; *count > int64(u.opts.MaxFiles) {
+		return ErrTooManyFiles
+	}
+	filename := Sanitize(part.FileName())
+	if filename == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidFilename, part.FileName())
+	}
+	br := bufio.NewReaderSize(u.limit(part, u.opts.MaxFileSize, total, ErrFileTooLarge), sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if len(head) == 0 {
+		// skip empty file input
+		return nil
+	}
+	f := File{Field: field, Filename: filename, ContentType: http.DetectContentType(head)}
+	if !u.allow(f.ContentType) {
+		return fmt.Errorf("%w: %s is %s", ErrTypeNotAllowed, filename, f.ContentType)
+	}
+	f.Name = u.opts.Name(&f)
+	h := sha256.New()
+	cr := &countReader{r: io.TeeReader(br, h)}
+	if f.Location, err = u.storage.Save(ctx, f.Name, cr); err != nil {
+		return err
+	}
+	f.Size, f.SHA256 = cr.n, hexSum(h)
+	res.Files[field] = append(res.Files[field], f)
+	return nil
+}
+
+func (u *Uploader) accept(field string) bool {
+	if len(u.opts.Fields) == 0 {
+		return true
+	}
+	for _, f := range u.opts.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Check content type by Options.Allowed, params like charset are ignored.
+func (u *Uploader) allow(contentType string) bool {
+	if len(u.opts.Allowed) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range u.opts.Allowed {
+		if allowed == mediaType || allowed == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Limit r by max bytes and the total size, err is returned when exceeded.
+func (u *Uploader) limit(r io.Reader, max int64, total *int64, err error) io.Reader {
+	return &limitReader{r: r, max: max, total: total, totalMax: u.opts.MaxTotalSize, err: err}
+}
+
+type limitReader struct {
+	r        io.Reader
+	n, max   int64
+	total    *int64
+	totalMax int64
+	err      error
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	*l.total += int64(n)
+	if l.n > l.max {
+		return n, l.err
+	}
+	if l.totalMax > 0 && *l.total > l.totalMax {
+		return n, ErrBodyTooLarge
+	}
+	return n, err
+}
+
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func hexSum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Sanitize a file name from client. Directories are dropped, and control
+// chars and chars not safe in file systems are replaced by '_'. It returns
+// "" when nothing is left.
+func Sanitize(name string) string {
+	// clients on windows may send full path
+	name = path.Base(filepath.ToSlash(strings.ReplaceAll(name, `\`, "/")))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(strings.TrimSpace(name), ".")
+	if len(name) > 255 {
+		ext := path.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		name = strings.ToValidUTF8(name[:255-len(ext)], "") + ext
+	}
+	return name
+}
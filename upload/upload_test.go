@@ -0,0 +1,193 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DOVECYJ/phoenix/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+var png = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 100)...)
+
+func multipartBody(t *testing.T, files [][2]string, values map[string]string) (*bytes.Buffer, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for k, v := range values {
+		mw.WriteField(k, v)
+	}
+	for _, f := range files {
+		w, err := mw.CreateFormFile("files", f[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(f[1]))
+	}
+	mw.Close()
+	return &buf, mw.FormDataContentType()
+}
+
+func TestReceive(t *testing.T) {
+	dir := t.TempDir()
+	u := New(Dir(dir), Options{Allowed: []string{"image/*"}})
+	body, ct := multipartBody(t, [][2]string{{`..\..\a.png`, string(png)}}, map[string]string{"title": "hi"})
+	r := httptest.NewRequest("POST", "/", body)
+	r.Header.Set("Content-Type", ct)
+
+	res, err := u.Receive(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, ok := res.File("files")
+	if !ok || f.Filename != "a.png" || f.ContentType != "image/png" || f.Size != int64(len(png)) {
+		t.Fatalf("unexpected file %+v", f)
+	}
+	sum := sha256.Sum256(png)
+	if f.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("sha256 = %s", f.SHA256)
+	}
+	if bs, _ := os.ReadFile(filepath.Join(dir, "a.png")); !bytes.Equal(bs, png) {
+		t.Error("file not saved")
+	}
+	if res.Values.Get("title") != "hi" {
+		t.Errorf("values = %v", res.Values)
+	}
+}
+
+func TestReceiveLimits(t *testing.T) {
+	cases := []struct {
+		name  string
+		opts  Options
+		files [][2]string
+		want  error
+	}{
+		{"type", Options{Allowed: []string{"image/*"}}, [][2]string{{"a.txt", "hello"}}, ErrTypeNotAllowed},
+		{"size", Options{MaxFileSize: 10}, [][2]string{{"a.txt", strings.Repeat("a", 11)}}, ErrFileTooLarge},
+		{"total", Options{MaxTotalSize: 15}, [][2]string{{"a.txt", "0123456789"}, {"b.txt", "0123456789"}}, ErrBodyTooLarge},
+		{"count", Options{MaxFiles: 1}, [][2]string{{"a.txt", "a"}, {"b.txt", "b"}}, ErrTooManyFiles},
+		{"exist", Options{}, [][2]string{{"a.txt", "a"}, {"a.txt", "b"}}, os.ErrExist},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			body, ct := multipartBody(t, c.files, nil)
+			r := httptest.NewRequest("POST", "/", body)
+			r.Header.Set("Content-Type", ct)
+			if _, err := New(Dir(dir), c.opts).Receive(r); !errors.Is(err, c.want) {
+				t.Fatalf("err = %v, want %v", err, c.want)
+			}
+			// no partial or temporary file is left
+			entries, _ := os.ReadDir(dir)
+			for _, e := range entries {
+				if strings.HasPrefix(e.Name(), ".upload-") {
+					t.Errorf("temporary file %s is left", e.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestReceiveBehindMiddlewares(t *testing.T) {
+	const token = "0123456789012345678901234567890123456789abc"
+	u := New(Dir(t.TempDir()), Options{})
+	r := chi.NewRouter()
+	r.Use(middleware.MethodSpoofing, middleware.CSRF)
+	r.Put("/", func(w http.ResponseWriter, r *http.Request) {
+		res, err := u.Receive(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f, _ := res.File("files")
+		w.Write([]byte(f.Filename + " " + res.Values.Get(middleware.CSRFField)))
+	})
+
+	for _, sent := range []string{token, "wrong"} {
+		body, ct := multipartBody(t, [][2]string{{"a.png", string(png)}}, map[string]string{
+			"_method":            "PUT",
+			middleware.CSRFField: sent,
+		})
+		req := httptest.NewRequest("POST", "/", body)
+		req.Header.Set("Content-Type", ct)
+		req.AddCookie(&http.Cookie{Name: middleware.CSRFCookie, Value: token})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if sent == token && (w.Code != http.StatusOK || w.Body.String() != "a.png "+token) {
+			t.Errorf("valid token: %d %s", w.Code, w.Body)
+		}
+		if sent != token && w.Code != http.StatusForbidden {
+			t.Errorf("wrong token: %d %s", w.Code, w.Body)
+		}
+	}
+
+	// body parsed by a handler before
+	body, ct := multipartBody(t, nil, map[string]string{"title": "hi"})
+	req := httptest.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", ct)
+	req.FormValue("title")
+	if _, err := u.Receive(req); !errors.Is(err, ErrBodyParsed) {
+		t.Errorf("err = %v, want %v", err, ErrBodyParsed)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	for in, want := range map[string]string{
+		"a.png":             "a.png",
+		"../../etc/passwd":  "passwd",
+		`C:\Users\me\a.png`: "a.png",
+		"..":                "",
+		"a\x00b?.txt":       "a_b_.txt",
+	} {
+		if got := Sanitize(in); got != want {
+			t.Errorf("Sanitize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	save := func(s Storage, content string) error {
+		_, err := s.Save(context.Background(), "a/b.txt", strings.NewReader(content))
+		return err
+	}
+	read := func() string {
+		bs, _ := os.ReadFile(filepath.Join(dir, "a/b.txt"))
+		return string(bs)
+	}
+	if err := save(Dir(dir), "one"); err != nil || read() != "one" {
+		t.Fatalf("save: %v %q", err, read())
+	}
+	if err := save(Dir(dir), "two"); !errors.Is(err, os.ErrExist) || read() != "one" {
+		t.Errorf("save existing: %v %q", err, read())
+	}
+	if err := save(Dir(dir, true), "three"); err != nil || read() != "three" {
+		t.Errorf("replace: %v %q", err, read())
+	}
+	if _, err := Dir(dir).Save(context.Background(), "../x.txt", strings.NewReader("")); !errors.Is(err, ErrInvalidFilename) {
+		t.Errorf("escape: %v", err)
+	}
+
+	// the copy used without hard links never replaces either
+	src := filepath.Join(dir, "src")
+	os.WriteFile(src, []byte("four"), 0o644)
+	if err := copyExcl(src, filepath.Join(dir, "a/b.txt")); !errors.Is(err, os.ErrExist) || read() != "three" {
+		t.Errorf("copy existing: %v %q", err, read())
+	}
+	if err := copyExcl(src, filepath.Join(dir, "c.txt")); err != nil {
+		t.Errorf("copy: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "a")); len(entries) != 1 {
+		t.Errorf("temporary files are left: %v", entries)
+	}
+}